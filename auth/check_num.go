@@ -2,14 +2,24 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	g79 "github.com/Yeah114/g79client"
 	"github.com/Yeah114/unmcpk"
 )
 
-// TransferCheckNum 调用 unmcpk 模块生成校验值
+// 校验值生成后端，通过 CheckNumOptions.Backend 选择。
+const (
+	// CheckNumBackendPython 使用 unmcpk 调用 python3 生成（默认）。
+	CheckNumBackendPython = "python"
+	// CheckNumBackendPool 通过常驻 worker 进程池生成，避免每次调用都启动新进程。
+	CheckNumBackendPool = "pool"
+)
+
+// TransferCheckNum 根据配置的后端生成校验值，相同输入的结果会被缓存（见 WithoutCheckNumCache）
 func TransferCheckNum(ctx context.Context, isPC bool, data, engineVersion, patchVersion string) (string, error) {
 	if engineVersion == "" {
 		engineVersion = g79.EngineVersion
@@ -22,10 +32,64 @@ func TransferCheckNum(ctx context.Context, isPC bool, data, engineVersion, patch
 		patchVersion = latestVersion
 	}
 
-	python3Path := os.Getenv("FUNAUTH_PYTHON3")
-	value, err := unmcpk.GenerateTransferCheckNum(isPC, data, engineVersion, patchVersion, python3Path)
+	cache := getCheckNumCache()
+	key := checkNumCacheKey(isPC, data, engineVersion, patchVersion)
+	start := time.Now()
+	if cache != nil && !checkNumCacheBypassed(ctx) {
+		if value, ok := cache.Get(key); ok {
+			checkNumDuration.ObserveSince(start, "cache", "ok")
+			return value, nil
+		}
+	}
+	value, err := generateTransferCheckNum(ctx, isPC, data, engineVersion, patchVersion)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	checkNumDuration.ObserveSince(start, CheckNumBackend(), outcome)
+	if err == nil && cache != nil {
+		cache.Put(key, value)
+	}
+	return value, err
+}
+
+func generateTransferCheckNum(ctx context.Context, isPC bool, data, engineVersion, patchVersion string) (string, error) {
+	switch CheckNumBackend() {
+	case CheckNumBackendPool:
+		return poolTransferCheckNum(ctx, isPC, data, engineVersion, patchVersion)
+	default:
+		return pythonTransferCheckNum(isPC, data, engineVersion, patchVersion)
+	}
+}
+
+// CloseCheckNum 在服务关闭时调用：结束常驻 worker 池，停止缓存的定时写回并把尚未写回的条目保存到持久化文件。
+func CloseCheckNum() error {
+	var errs []error
+	if checkNumPool != nil {
+		errs = append(errs, checkNumPool.Close())
+	}
+	if checkNumCacheStop != nil {
+		if err := checkNumCacheStop(); err != nil {
+			errs = append(errs, fmt.Errorf("save check num cache: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func pythonTransferCheckNum(isPC bool, data, engineVersion, patchVersion string) (string, error) {
+	value, err := unmcpk.GenerateTransferCheckNum(isPC, data, engineVersion, patchVersion, python3Path())
 	if err != nil {
 		return "", err
 	}
 	return value, nil
 }
+
+func CheckNumBackend() string {
+	backend := strings.ToLower(strings.TrimSpace(currentOptions().CheckNum.Backend))
+	switch backend {
+	case CheckNumBackendPool:
+		return backend
+	default:
+		return CheckNumBackendPython
+	}
+}
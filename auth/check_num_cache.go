@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/lru"
+)
+
+const (
+	defaultCheckNumCacheSize = 1024
+	checkNumCacheFlushEvery  = 30 * time.Second
+)
+
+type checkNumCacheBypassKey struct{}
+
+var (
+	checkNumCacheOnce sync.Once
+	checkNumCache     *lru.Cache
+	// checkNumCacheStop 结束持久化文件的定时写回并保存剩余变更，未配置持久化时为 nil。
+	checkNumCacheStop func() error
+)
+
+// WithoutCheckNumCache 返回的 ctx 会让 TransferCheckNum 跳过缓存读取并以新结果覆盖缓存。
+func WithoutCheckNumCache(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, checkNumCacheBypassKey{}, true)
+}
+
+// CheckNumCacheStats 返回校验值缓存的命中统计；未启用时 ok 为 false。
+func CheckNumCacheStats() (stats lru.Stats, ok bool) {
+	cache := getCheckNumCache()
+	if cache == nil {
+		return stats, false
+	}
+	return cache.Stats(), true
+}
+
+func checkNumCacheKey(isPC bool, data, engineVersion, patchVersion string) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatBool(isPC)))
+	for _, part := range []string{data, engineVersion, patchVersion} {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func checkNumCacheBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypass, _ := ctx.Value(checkNumCacheBypassKey{}).(bool)
+	return bypass
+}
+
+// getCheckNumCache 按 CheckNumOptions 的 CacheSize 与 CacheFile 创建缓存，CacheSize 为 0 时不启用缓存。
+func getCheckNumCache() *lru.Cache {
+	checkNumCacheOnce.Do(func() {
+		opts := currentOptions().CheckNum
+		size := defaultCheckNumCacheSize
+		if opts.CacheSize != nil {
+			size = *opts.CacheSize
+		}
+		if size <= 0 {
+			return
+		}
+		cache := lru.New(size)
+		if path := opts.CacheFile; path != "" {
+			stop, err := cache.Persist(path, checkNumCacheFlushEvery)
+			if err != nil {
+				log.Printf("[checknum] load cache file %s: %v", path, err)
+			}
+			checkNumCacheStop = stop
+		}
+		checkNumCache = cache
+	})
+	return checkNumCache
+}
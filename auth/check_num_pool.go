@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/workerpool"
+)
+
+const (
+	defaultCheckNumPoolSize    = 2
+	defaultCheckNumPoolTimeout = 15 * time.Second
+)
+
+// checkNumWorkerRequest/checkNumWorkerResponse 为常驻 worker 的单行 JSON 协议。
+type checkNumWorkerRequest struct {
+	ID            uint64 `json:"id"`
+	IsPC          bool   `json:"is_pc"`
+	Data          string `json:"data"`
+	EngineVersion string `json:"engine_version"`
+	PatchVersion  string `json:"patch_version"`
+}
+
+type checkNumWorkerResponse struct {
+	ID    uint64 `json:"id"`
+	Value string `json:"value,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	checkNumPoolOnce sync.Once
+	checkNumPool     *workerpool.Pool
+	checkNumPoolErr  error
+	checkNumPoolSeq  atomic.Uint64
+)
+
+// CheckNumPoolStats 返回常驻 worker 池的状态；未启用时 ok 为 false。
+func CheckNumPoolStats() (stats workerpool.Stats, ok bool) {
+	if checkNumPool == nil {
+		return stats, false
+	}
+	return checkNumPool.Stats(), true
+}
+
+func poolTransferCheckNum(ctx context.Context, isPC bool, data, engineVersion, patchVersion string) (string, error) {
+	checkNumPoolOnce.Do(func() {
+		checkNumPool, checkNumPoolErr = newCheckNumPool()
+	})
+	if checkNumPoolErr != nil {
+		return "", checkNumPoolErr
+	}
+
+	req := checkNumWorkerRequest{
+		ID:            checkNumPoolSeq.Add(1),
+		IsPC:          isPC,
+		Data:          data,
+		EngineVersion: engineVersion,
+		PatchVersion:  patchVersion,
+	}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	out, err := checkNumPool.Call(ctx, line)
+	if err != nil {
+		return "", fmt.Errorf("check num worker: %w", err)
+	}
+	var resp checkNumWorkerResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("check num worker: bad response: %w", err)
+	}
+	if resp.ID != req.ID {
+		return "", fmt.Errorf("check num worker: response id %d, want %d", resp.ID, req.ID)
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return resp.Value, nil
+}
+
+// newCheckNumPool 按 CheckNumOptions 的 WorkerCmd、PoolSize 与 PoolTimeout 创建 worker 池。
+func newCheckNumPool() (*workerpool.Pool, error) {
+	opts := currentOptions().CheckNum
+	command := opts.WorkerCmd
+	if len(command) == 0 {
+		self, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("resolve executable: %w", err)
+		}
+		command = []string{self, "checknum-worker"}
+	}
+
+	size := opts.PoolSize
+	if size <= 0 {
+		size = defaultCheckNumPoolSize
+	}
+	timeout := opts.PoolTimeout
+	if timeout <= 0 {
+		timeout = defaultCheckNumPoolTimeout
+	}
+
+	return workerpool.New(workerpool.Config{
+		Name:    "checknum",
+		Command: command,
+		Size:    size,
+		Timeout: timeout,
+	})
+}
+
+// ServeCheckNumWorker 以 worker 身份处理按行分隔的请求，直到 r 结束。
+//
+// 每行请求为 {"id","is_pc","data","engine_version","patch_version"}，
+// 每行应答为 {"id","value"} 或 {"id","error"}。
+// 每个请求直接调用 unmcpk（Options.Python3）；worker 被进程池结束时，其启动的 python 进程随进程组一起结束。
+func ServeCheckNumWorker(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for sc.Scan() {
+		var req checkNumWorkerRequest
+		var resp checkNumWorkerResponse
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("bad request: %v", err)
+		} else {
+			resp.ID = req.ID
+			var err error
+			resp.Value, err = pythonTransferCheckNum(req.IsPC, req.Data, req.EngineVersion, req.PatchVersion)
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
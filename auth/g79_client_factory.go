@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/http"
 
 	g79 "github.com/Yeah114/g79client"
 
@@ -21,12 +22,34 @@ func NewG79Client(ctx context.Context) (*g79.Client, error) {
 	httpClient, err := proxy.NewHTTPClient(ctx)
 	if err != nil {
 		if errors.Is(err, proxy.ErrProxyDisabled) {
-			log.Printf("[proxy] FUNAUTH_PROXY_API_URL not set, using direct connection")
-			return g79.NewClient()
+			log.Printf("[proxy] no proxy source configured, using direct connection")
+			return newDirectG79Client()
 		}
 		return nil, err
 	}
 
 	log.Printf("[proxy] using rotating proxy client for g79 requests")
+	httpClient.Transport = instrumentTransport(httpClient.Transport)
 	return g79.NewClientWithHTTPClient(httpClient)
 }
+
+// newDirectG79Client 创建直连客户端：保留 g79.NewClient 自带的 http.Client 设置（超时、Cookie 等），
+// 只在其 Transport 外包一层上游请求指标。
+func newDirectG79Client() (*g79.Client, error) {
+	cli, err := g79.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if cli.HTTPClient == nil {
+		return cli, nil
+	}
+	transport := cli.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	// 复制后再替换 Transport，以免 g79.NewClient 返回共用的 http.Client 时被重复包装
+	httpClient := *cli.HTTPClient
+	httpClient.Transport = instrumentTransport(transport)
+	cli.HTTPClient = &httpClient
+	return cli, nil
+}
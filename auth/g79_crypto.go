@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/Yeah114/g79client/utils"
+)
+
+// G79Decrypt 解密 G79 HTTP 加密的十六进制内容。
+func G79Decrypt(contentHex string) (string, error) {
+	return utils.G79HttpDecrypt(strings.TrimSpace(contentHex))
+}
+
+// G79Encrypt 以 G79 HTTP 方式加密明文，返回十六进制内容。
+func G79Encrypt(plain string) (string, error) {
+	return utils.G79HttpEncrypt(plain)
+}
+
+// PrettyPayload 尝试将明文按 JSON 缩进格式化，非 JSON 时原样返回。
+func PrettyPayload(plain string) string {
+	trimmed := strings.TrimSpace(plain)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return plain
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return plain
+	}
+	return buf.String()
+}
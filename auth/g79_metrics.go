@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/metrics"
+)
+
+var (
+	g79RequestDuration = metrics.Default.NewHistogramVec(
+		"funauth_g79_request_duration_seconds",
+		"Latency of upstream g79 HTTP requests by host, path and status code.",
+		nil, "host", "path", "status")
+	g79ErrorCodes = metrics.Default.NewCounterVec(
+		"funauth_g79_error_codes_total",
+		"Non-zero codes returned by upstream g79 APIs, by failing step.",
+		"step", "code")
+	checkNumDuration = metrics.Default.NewHistogramVec(
+		"funauth_check_num_duration_seconds",
+		"Duration of TransferCheckNum by backend and outcome (cache hits use backend=cache).",
+		nil, "backend", "outcome")
+)
+
+// g79Transport 记录每个上游请求的耗时与状态码。
+type g79Transport struct {
+	base http.RoundTripper
+}
+
+func instrumentTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &g79Transport{base: base}
+}
+
+func (t *g79Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	g79RequestDuration.ObserveSince(start, req.URL.Host, normalizeMetricPath(req.URL.Path), status)
+	return resp, err
+}
+
+// normalizeMetricPath 把路径中的数字段替换为 :id，避免房间号等导致标签数量无限增长。
+func normalizeMetricPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(part, 10, 64); err == nil {
+			parts[i] = ":id"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// RecordErrorCode 从 "Step: message(code)" 形式的错误中提取上游返回码并计数。
+func RecordErrorCode(err error) {
+	step := ErrorStep(err)
+	if step == "" {
+		return
+	}
+	msg := err.Error()
+	if !strings.HasSuffix(msg, ")") {
+		return
+	}
+	open := strings.LastIndexByte(msg, '(')
+	if open < 0 {
+		return
+	}
+	code := msg[open+1 : len(msg)-1]
+	if _, convErr := strconv.Atoi(code); convErr != nil {
+		return
+	}
+	g79ErrorCodes.Inc(step, code)
+}
@@ -6,8 +6,11 @@ import (
 	"github.com/Yeah114/g79client"
 )
 
-var DefaultSkinItemID = "4672395235685216085"
-func GetSkinInfo(cli *g79client.Client) (SkinInfo, error) {
+// DefaultSkinItemID 为未配置默认皮肤时使用的皮肤。
+const DefaultSkinItemID = "4672395235685216085"
+
+// GetSkinInfo 返回账号的皮肤；账号未设置皮肤时先换上 defaultItemID。
+func GetSkinInfo(cli *g79client.Client, defaultItemID string) (SkinInfo, error) {
 	userSettingList, err := cli.GetUserSettingList()
 	if err != nil {
 		return SkinInfo{}, fmt.Errorf("GetUserSettingList: %w", err)
@@ -17,13 +20,13 @@ func GetSkinInfo(cli *g79client.Client) (SkinInfo, error) {
 	}
 	itemID := userSettingList.Entity.SkinData.ItemID
 	if itemID == "-1" || itemID == "" {
-		if DefaultSkinItemID == "" {
+		if defaultItemID == "" {
 			return SkinInfo{}, fmt.Errorf("ChangeSkin: missing default skin id")
 		}
-		if err := cli.ChangeSkin(DefaultSkinItemID); err != nil {
+		if err := cli.ChangeSkin(defaultItemID); err != nil {
 			return SkinInfo{}, fmt.Errorf("ChangeSkin: %w", err)
 		}
-		itemID = DefaultSkinItemID
+		itemID = defaultItemID
 	}
 	downloadInfo, err := cli.GetDownloadInfo(itemID)
 	if err != nil {
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CheckPython 检查 TransferCheckNum 使用的 python3（Options.Python3，默认 python3）能否运行，返回其版本。
+func CheckPython(ctx context.Context) (string, error) {
+	python3 := python3Path()
+	out, err := exec.CommandContext(ctx, python3, "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", python3, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// TransferDirectoryStatus 为联机中转服务器目录的状态。
+type TransferDirectoryStatus struct {
+	// Available 为最近一次成功拉取时可用服务器的数量。
+	Available int
+	// Age 为距最近一次成功拉取的时间。
+	Age time.Duration
+	// RefreshErr 为本次拉取失败的原因；目录未超过最长时间时不视为检查失败。
+	RefreshErr error
+}
+
+// CheckTransferServers 重新拉取本地联机中转服务器列表。拉取失败或没有可用服务器时，
+// 若最近一次成功拉取距今不超过 maxAge 仍视为正常，否则返回错误。
+func CheckTransferServers(ctx context.Context, maxAge time.Duration) (TransferDirectoryStatus, error) {
+	done := make(chan error, 1)
+	go func() {
+		servers, err := transferServers()
+		if err == nil && availableTransferServers(servers) == 0 {
+			err = errors.New("no available transfer server")
+		}
+		done <- err
+	}()
+	var refreshErr error
+	select {
+	case refreshErr = <-done:
+	case <-ctx.Done():
+		refreshErr = ctx.Err()
+	}
+
+	transferDirectory.mu.Lock()
+	st := TransferDirectoryStatus{Available: transferDirectory.available, RefreshErr: refreshErr}
+	fetchedAt := transferDirectory.fetchedAt
+	transferDirectory.mu.Unlock()
+	if fetchedAt.IsZero() {
+		return st, refreshErr
+	}
+	st.Age = time.Since(fetchedAt)
+	if refreshErr != nil && st.Age > maxAge {
+		return st, fmt.Errorf("transfer server directory is %s old (max %s): %w", st.Age.Round(time.Second), maxAge, refreshErr)
+	}
+	return st, nil
+}
+
+// CheckCookie 使用 cookie 完成一次 G79 认证，返回对应账号的 user id。
+func CheckCookie(ctx context.Context, cookie string) (string, error) {
+	cli, err := NewG79Client(ctx)
+	if err != nil {
+		return "", err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cli.G79AuthenticateWithCookie(cookie) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+		return cli.UserID, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -20,6 +20,9 @@ func Login(ctx context.Context, cli *g79.Client, p LoginParams) (LoginResult, er
 	if cli == nil {
 		return result, fmt.Errorf("nil client")
 	}
+	if err := waitLoginTurn(ctx, cli); err != nil {
+		return result, err
+	}
 
 	// 确保用户详情可用，用于昵称与等级
 	if cli.UserDetail == nil {
@@ -278,6 +281,10 @@ func Login(ctx context.Context, cli *g79.Client, p LoginParams) (LoginResult, er
 			}
 		}
 
+		// 加入之后必须完成进入/离开流程，已被取消（如服务关闭）时不再加入
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("JoinDomainServerWithInviteCode: %w", err)
+		}
 		// 通过邀请码加入山头服务器
 		inviteResp, err := cli.JoinDomainServerWithInviteCode(inviteCode)
 		if err != nil {
@@ -346,6 +353,10 @@ func Login(ctx context.Context, cli *g79.Client, p LoginParams) (LoginResult, er
 			}
 		}
 
+		// 加入之后必须完成进入/离开流程，已被取消（如服务关闭）时不再加入
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("JoinDomainServerWithInviteCode: %w", err)
+		}
 		// 通过邀请码加入山头服务器
 		inviteResp, err := cli.JoinDomainServerWithInviteCode(inviteCode)
 		if err != nil {
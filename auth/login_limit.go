@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	g79 "github.com/Yeah114/g79client"
+
+	"github.com/Yeah114/FunAuth/internal/ratelimit"
+)
+
+const (
+	defaultLoginAccountLimit = "6/m:2"
+	defaultLoginMaxWait      = 30 * time.Second
+)
+
+// RateLimitError 表示同一账号登录过于频繁，排队时间超过上限。
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many logins for this account, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+var (
+	loginLimiterOnce sync.Once
+	loginLimiter     *ratelimit.Limiter
+	loginMaxWait     atomic.Int64
+)
+
+// LoginLimit 为按账号区分的登录限流参数，零值字段使用默认值。
+type LoginLimit struct {
+	// Account 为每个网易账号的登录频率，格式见 ratelimit.ParseSpec，默认 6/m:2，off 关闭。
+	Account string
+	// MaxWait 为单次登录最长排队时间，默认 30s。
+	MaxWait time.Duration
+}
+
+func (l LoginLimit) resolve() (ratelimit.Spec, time.Duration) {
+	raw := l.Account
+	if strings.TrimSpace(raw) == "" {
+		raw = defaultLoginAccountLimit
+	}
+	spec, err := ratelimit.ParseSpec(raw)
+	if err != nil {
+		log.Printf("[auth] %v, using %s", err, defaultLoginAccountLimit)
+		spec, _ = ratelimit.ParseSpec(defaultLoginAccountLimit)
+	}
+	maxWait := l.MaxWait
+	if maxWait <= 0 {
+		maxWait = defaultLoginMaxWait
+	}
+	return spec, maxWait
+}
+
+func getLoginLimiter() *ratelimit.Limiter {
+	loginLimiterOnce.Do(func() {
+		spec, maxWait := LoginLimit{}.resolve()
+		loginLimiter = ratelimit.New(spec)
+		loginMaxWait.Store(int64(maxWait))
+	})
+	return loginLimiter
+}
+
+// ReloadLoginLimiter 以 l 更新账号登录限流参数，启动时以及配置热更新后调用。
+func ReloadLoginLimiter(l LoginLimit) {
+	limiter := getLoginLimiter()
+	spec, maxWait := l.resolve()
+	limiter.SetSpec(spec)
+	loginMaxWait.Store(int64(maxWait))
+}
+
+// waitLoginTurn 在向上游发起登录前按账号排队，避免同一账号短时间内的大量登录触发上游的“操作过于频繁”。
+func waitLoginTurn(ctx context.Context, cli *g79.Client) error {
+	if cli == nil || cli.UserID == "" {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	limiter := getLoginLimiter()
+	wait, err := limiter.Wait(ctx, cli.UserID, time.Duration(loginMaxWait.Load()))
+	if err == ratelimit.ErrWaitTooLong {
+		return &RateLimitError{RetryAfter: wait}
+	}
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		log.Printf("[auth] login for account %s queued for %s", cli.UserID, wait.Round(time.Millisecond))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 登录模式，由 LoginParams.ServerCode 的前缀决定。
+const (
+	LoginModeLobby    = "LobbyGame"
+	LoginModePCLobby  = "PCLobbyGame"
+	LoginModeNetwork  = "NetworkGame"
+	LoginModeDomain   = "DomainGame"
+	LoginModePCDomain = "PCDomainGame"
+	LoginModeRental   = "RentalServer"
+)
+
+// LoginMode 返回 serverCode 对应的登录模式，与 Login 的分支保持一致。
+func LoginMode(serverCode string) string {
+	for _, mode := range []string{LoginModeLobby, LoginModePCLobby, LoginModeNetwork, LoginModeDomain, LoginModePCDomain} {
+		if after, ok := strings.CutPrefix(serverCode, mode+":"); ok && after != "" {
+			return mode
+		}
+	}
+	return LoginModeRental
+}
+
+// ErrorStep 返回 Login 等函数错误信息中的失败步骤（如 "GetOnlineLobbyRoom"），无法识别时返回空串。
+func ErrorStep(err error) string {
+	if err == nil {
+		return ""
+	}
+	step, _, ok := strings.Cut(err.Error(), ":")
+	if !ok || step == "" {
+		return ""
+	}
+	for _, r := range step {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
+	}
+	return step
+}
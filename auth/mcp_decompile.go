@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Yeah114/unmcpk"
+)
+
+const defaultMCPDecompileConcurrency = 2
+
+// ErrMCPDecompileBusy 表示正在运行的反编译数量已达上限。
+var ErrMCPDecompileBusy = errors.New("too many mcp decompile jobs running")
+
+var (
+	mcpDecompileSlotsOnce sync.Once
+	mcpDecompileSlots     chan struct{}
+)
+
+// getMCPDecompileSlots 按 Options.MCPDecompileConcurrency（默认 2）创建并发槽位。
+func getMCPDecompileSlots() chan struct{} {
+	mcpDecompileSlotsOnce.Do(func() {
+		n := currentOptions().MCPDecompileConcurrency
+		if n <= 0 {
+			n = defaultMCPDecompileConcurrency
+		}
+		mcpDecompileSlots = make(chan struct{}, n)
+	})
+	return mcpDecompileSlots
+}
+
+// DecompileMCP 使用 unmcpk 反编译服务器下发的 dynamic MCP。
+//
+// unmcpk 本身不支持取消，ctx 结束时本函数立即返回错误，python 进程仍会运行到结束。
+// 为避免超时的进程不断累积，每个进程占用一个并发槽位直到真正结束，槽位用尽时直接返回 ErrMCPDecompileBusy。
+func DecompileMCP(ctx context.Context, mcp []byte) (string, error) {
+	if len(mcp) == 0 {
+		return "", errors.New("empty mcp")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	slots := getMCPDecompileSlots()
+	select {
+	case slots <- struct{}{}:
+	default:
+		return "", ErrMCPDecompileBusy
+	}
+	python3 := python3Path()
+
+	type result struct {
+		text string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-slots }()
+		out, err := unmcpk.DecompileDynamicMCP(mcp, python3)
+		done <- result{text: string(out), err: err}
+	}()
+	select {
+	case res := <-done:
+		return res.text, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("decompile mcp: %w", ctx.Err())
+	}
+}
+
+// MCPInputFormat 为 DecodeMCPInput 的输入格式。
+type MCPInputFormat string
+
+const (
+	// MCPInputAuto 按内容识别：以 '[' 开头为 data 字段，全为十六进制字符为 hex，否则为原始二进制。
+	// 原始二进制恰好满足前两种特征时会被误判，此时应显式指定格式。
+	MCPInputAuto MCPInputFormat = "auto"
+	// MCPInputRaw 为原始二进制。
+	MCPInputRaw MCPInputFormat = "raw"
+	// MCPInputHex 为十六进制文本。
+	MCPInputHex MCPInputFormat = "hex"
+	// MCPInputData 为 transfer_check_num 的 data 字段：["<mcpHex>","<val>",<unique_id>]
+	MCPInputData MCPInputFormat = "data"
+)
+
+// ParseMCPInputFormat 解析 auto/raw/hex/data，空字符串为 auto。
+func ParseMCPInputFormat(s string) (MCPInputFormat, error) {
+	switch f := MCPInputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return MCPInputAuto, nil
+	case MCPInputAuto, MCPInputRaw, MCPInputHex, MCPInputData:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown mcp input format %q, want auto, raw, hex or data", s)
+	}
+}
+
+// DecodeMCPInput 按 format 解码 MCP 输入。
+func DecodeMCPInput(raw []byte, format MCPInputFormat) ([]byte, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, errors.New("empty input")
+	}
+	if format == MCPInputAuto {
+		switch {
+		case trimmed[0] == '[':
+			format = MCPInputData
+		case isHexText(trimmed):
+			format = MCPInputHex
+		default:
+			format = MCPInputRaw
+		}
+	}
+	switch format {
+	case MCPInputRaw:
+		return raw, nil
+	case MCPInputHex:
+		mcp, err := hex.DecodeString(string(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("bad mcp hex: %w", err)
+		}
+		return mcp, nil
+	case MCPInputData:
+		input, err := parseCheckNumData(string(trimmed))
+		if err != nil {
+			return nil, err
+		}
+		return input.MCP, nil
+	default:
+		return nil, fmt.Errorf("unknown mcp input format %q", format)
+	}
+}
+
+// checkNumInput 为 transfer_check_num 请求中的 data 字段：["<mcpHex>","<val>",<unique_id>]
+type checkNumInput struct {
+	MCP      []byte
+	Value    string
+	UniqueID json.Number
+}
+
+func parseCheckNumData(data string) (checkNumInput, error) {
+	var input checkNumInput
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var fields []any
+	if err := dec.Decode(&fields); err != nil || len(fields) < 3 {
+		return input, errors.New("bad data")
+	}
+	mcpHex, ok := fields[0].(string)
+	if !ok {
+		return input, errors.New("bad data")
+	}
+	mcp, err := hex.DecodeString(mcpHex)
+	if err != nil || len(mcp) == 0 {
+		return input, errors.New("bad mcp hex")
+	}
+	input.MCP = mcp
+	if input.Value, ok = fields[1].(string); !ok {
+		return input, errors.New("bad data")
+	}
+	if input.UniqueID, ok = fields[2].(json.Number); !ok {
+		return input, errors.New("bad data")
+	}
+	return input, nil
+}
+
+func isHexText(b []byte) bool {
+	if len(b)%2 != 0 {
+		return false
+	}
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Options 为 auth 包的运行参数，由 Configure 在启动时设置；未调用 Configure 时全部使用默认值。
+type Options struct {
+	// Python3 为 unmcpk 使用的 python3，默认 python3。
+	Python3 string
+	// CheckNum 为校验值生成配置。
+	CheckNum CheckNumOptions
+	// MCPDecompileConcurrency 为同时运行的 MCP 反编译进程数上限，默认 2。
+	MCPDecompileConcurrency int
+}
+
+// CheckNumOptions 为校验值生成配置，零值字段使用默认值。
+type CheckNumOptions struct {
+	// Backend 为 CheckNumBackendPython（默认）或 CheckNumBackendPool。
+	Backend string
+	// WorkerCmd 为 worker 命令及参数，为空时以 checknum-worker 子命令启动自身。
+	WorkerCmd []string
+	// PoolSize 为 worker 数量，默认 2。
+	PoolSize int
+	// PoolTimeout 为单次调用超时，默认 15s。
+	PoolTimeout time.Duration
+	// CacheSize 为缓存的最大条目数，nil 时为 1024，0 表示关闭缓存。
+	CacheSize *int
+	// CacheFile 为缓存的持久化文件，为空时仅保存在内存中。
+	CacheFile string
+}
+
+var options atomic.Pointer[Options]
+
+// Configure 设置运行参数；worker 池、缓存与反编译并发槽位在首次使用时按当时的参数创建，因此应在处理请求前调用。
+func Configure(opts Options) {
+	options.Store(&opts)
+}
+
+func currentOptions() Options {
+	if opts := options.Load(); opts != nil {
+		return *opts
+	}
+	return Options{}
+}
+
+// python3Path 返回 unmcpk 使用的 python3。
+func python3Path() string {
+	if p := currentOptions().Python3; p != "" {
+		return p
+	}
+	return "python3"
+}
@@ -1,19 +1,27 @@
 package auth
 
-import (
-	"github.com/Yeah114/g79client/utils"
-)
-
 // TransferStartType 解密 contentHex，拼接 uid 到明文前缀，再加密返回。
 func TransferStartType(uid, contentHex string) (string, error) {
-	plain, err := utils.G79HttpDecrypt(contentHex)
+	res, err := TransferStartTypeDetail(uid, contentHex)
 	if err != nil {
 		return "", err
 	}
-	merged := uid + plain
-	enc, err := utils.G79HttpEncrypt(merged)
+	return res.Encrypted, nil
+}
+
+// TransferStartTypeDetail 与 TransferStartType 相同，但同时返回解密后的明文与拼接结果。
+func TransferStartTypeDetail(uid, contentHex string) (TransferStartTypeResult, error) {
+	var res TransferStartTypeResult
+	plain, err := G79Decrypt(contentHex)
+	if err != nil {
+		return res, err
+	}
+	res.Plaintext = plain
+	res.Merged = uid + plain
+	enc, err := G79Encrypt(res.Merged)
 	if err != nil {
-		return "", err
+		return res, err
 	}
-	return enc, nil
+	res.Encrypted = enc
+	return res, nil
 }
@@ -2,13 +2,14 @@ package auth
 
 import (
 	"context"
-	cryptoRand "crypto/rand"
 	"fmt"
 	"math/rand"
 	"time"
 
 	"github.com/Yeah114/g79client"
 	"github.com/Yeah114/g79client/utils"
+
+	"github.com/Yeah114/FunAuth/auth/tankey"
 )
 
 // TanLobbyCreate sets up transfer information required to host a tan lobby room.
@@ -18,6 +19,9 @@ func TanLobbyCreate(ctx context.Context, cli *g79client.Client) (TanLobbyCreateR
 	if cli == nil {
 		return result, fmt.Errorf("TanLobbyCreate: nil client")
 	}
+	if err := waitLoginTurn(ctx, cli); err != nil {
+		return result, err
+	}
 
 	if cli.UserToken == "" {
 		return result, fmt.Errorf("TanLobbyCreate: missing user token")
@@ -36,35 +40,9 @@ func TanLobbyCreate(ctx context.Context, cli *g79client.Client) (TanLobbyCreateR
 		return result, fmt.Errorf("TanLobbyCreate: %w", err)
 	}
 
-	encryptedToken := utils.GetEncryptedToken(cli.UserToken)
-
-	raknetRand := make([]byte, 16)
-	if _, err = cryptoRand.Read(raknetRand); err != nil {
-		return result, fmt.Errorf("TanLobbyCreate: rand read: %w", err)
-	}
-
-	raknetAESRand, err := utils.AesECBEncrypt(raknetRand, encryptedToken)
-	if err != nil {
-		return result, fmt.Errorf("TanLobbyCreate: aes encrypt: %w", err)
-	}
-	if len(raknetAESRand) >= 16 {
-		raknetAESRand = raknetAESRand[:16]
-	}
-
-	encryptKeyBytes := append(append(make([]byte, 0, len(encryptedToken)+len(raknetRand)), encryptedToken...), raknetRand...)
-	decryptKeyBytes := append(append(make([]byte, 0, len(encryptedToken)+len(raknetRand)), raknetRand...), encryptedToken...)
-
-	signalingSeed := make([]byte, 16)
-	if _, err = cryptoRand.Read(signalingSeed); err != nil {
-		return result, fmt.Errorf("TanLobbyCreate: rand read: %w", err)
-	}
-
-	signalingTicket, err := utils.AesECBEncrypt(signalingSeed, []byte(cli.UserToken))
+	keys, err := tankey.New(nil).Generate(cli.UserToken, utils.GetEncryptedToken(cli.UserToken), tankey.FormatBlock)
 	if err != nil {
-		return result, fmt.Errorf("TanLobbyCreate: aes encrypt: %w", err)
-	}
-	if len(signalingTicket) >= 16 {
-		signalingTicket = signalingTicket[:16]
+		return result, fmt.Errorf("TanLobbyCreate: %w", err)
 	}
 
 	uid, err := cli.GetUserIDInt()
@@ -80,13 +58,13 @@ func TanLobbyCreate(ctx context.Context, cli *g79client.Client) (TanLobbyCreateR
 	result.UserUniqueID = uint32(uid)
 	result.UserPlayerName = playerName
 	result.RaknetServerAddress = raknetAddr
-	result.RaknetRand = raknetRand
-	result.RaknetAESRand = raknetAESRand
-	result.EncryptKeyBytes = encryptKeyBytes
-	result.DecryptKeyBytes = decryptKeyBytes
+	result.RaknetRand = keys.RaknetRand
+	result.RaknetAESRand = keys.RaknetAESRand
+	result.EncryptKeyBytes = keys.EncryptKeyBytes
+	result.DecryptKeyBytes = keys.DecryptKeyBytes
 	result.SignalingServerAddress = signalingAddr
-	result.SignalingSeed = signalingSeed
-	result.SignalingTicket = signalingTicket
+	result.SignalingSeed = keys.SignalingSeed
+	result.SignalingTicket = keys.SignalingTicket
 
 	return result, nil
 }
@@ -99,7 +77,7 @@ type transferServerEntry struct {
 }
 
 func selectTransferServer(cli *g79client.Client) (string, string, error) {
-	servers, err := g79client.GetGlobalG79TransferServers()
+	servers, err := transferServers()
 	if err != nil {
 		return "", "", fmt.Errorf("SelectTransferServer: %w", err)
 	}
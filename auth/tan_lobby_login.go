@@ -2,18 +2,23 @@ package auth
 
 import (
 	"context"
-	cryptoRand "crypto/rand"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/Yeah114/g79client"
 	"github.com/Yeah114/g79client/utils"
+
+	"github.com/Yeah114/FunAuth/auth/tankey"
 )
 
 func TanLobbyLogin(ctx context.Context, cli *g79client.Client, p TanLobbyLoginParams) (TanLobbyLoginResult, error) {
 	var result TanLobbyLoginResult
 
+	if err := waitLoginTurn(ctx, cli); err != nil {
+		return result, err
+	}
+
 	roomInfo, err := cli.GetTransferRoomWithName(p.RoomID)
 	if err != nil {
 		return result, fmt.Errorf("get transfer room with name: %w", err)
@@ -31,28 +36,9 @@ func TanLobbyLogin(ctx context.Context, cli *g79client.Client, p TanLobbyLoginPa
 		}
 	}
 
-	encryptedToken := utils.GetEncryptedToken(cli.UserToken)
-	raknetRand := make([]byte, 16)
-	_, err = cryptoRand.Read(raknetRand)
-	if err != nil {
-		return result, fmt.Errorf("rand read: %w", err)
-	}
-	raknetAESRand, err := utils.AesECBEncrypt(raknetRand, encryptedToken)
-	if err != nil {
-		return result, fmt.Errorf("aes encrypt: %w", err)
-	}
-	encryptKeyBytes := append(encryptedToken, raknetRand...)
-	decryptKeyBytes := append(raknetRand, encryptedToken...)
-
-	seed := make([]byte, 16)
-	_, err = cryptoRand.Read(seed)
-	if err != nil {
-		return result, fmt.Errorf("rand read: %w", err)
-	}
-
-	ticket, err := utils.AesECBEncrypt(seed, []byte(cli.UserToken))
+	keys, err := tankey.New(nil).Generate(cli.UserToken, utils.GetEncryptedToken(cli.UserToken), tankey.FormatPadded)
 	if err != nil {
-		return result, fmt.Errorf("aes encrypt: %w", err)
+		return result, fmt.Errorf("TanLobbyLogin: %w", err)
 	}
 
 	target := roomInfo.List[0]
@@ -100,7 +86,7 @@ func TanLobbyLogin(ctx context.Context, cli *g79client.Client, p TanLobbyLoginPa
 
 	roomTransferServerID := int(target.SRV.Int64())
 	if roomTransferServerID != 0 {
-		servers, err := g79client.GetGlobalG79TransferServers()
+		servers, err := transferServers()
 		if err != nil {
 			return result, fmt.Errorf("get transfer servers: %w", err)
 		}
@@ -122,12 +108,12 @@ func TanLobbyLogin(ctx context.Context, cli *g79client.Client, p TanLobbyLoginPa
 	if result.RaknetServerAddress == "" || result.SignalingServerAddress == "" {
 		return result, fmt.Errorf("resolve transfer server address failed")
 	}
-	result.RaknetRand = raknetRand
-	result.RaknetAESRand = raknetAESRand
-	result.SignalingSeed = seed
-	result.SignalingTicket = ticket
-	result.EncryptKeyBytes = encryptKeyBytes
-	result.DecryptKeyBytes = decryptKeyBytes
+	result.RaknetRand = keys.RaknetRand
+	result.RaknetAESRand = keys.RaknetAESRand
+	result.SignalingSeed = keys.SignalingSeed
+	result.SignalingTicket = keys.SignalingTicket
+	result.EncryptKeyBytes = keys.EncryptKeyBytes
+	result.DecryptKeyBytes = keys.DecryptKeyBytes
 
 	return result, nil
 }
@@ -2,12 +2,46 @@ package auth
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/Yeah114/g79client"
 )
 
-func TransferServerList() ([]string, []string, error) {
+// transferDirectory 记录最近一次拉取到可用中转服务器的时间与数量，供就绪检查判断目录是否过旧。
+var transferDirectory struct {
+	mu        sync.Mutex
+	available int
+	fetchedAt time.Time
+}
+
+// transferServers 拉取联机中转服务器列表；列表中有可用服务器时记录本次拉取的时间。
+func transferServers() ([]g79client.TransferServer, error) {
 	servers, err := g79client.GetGlobalG79TransferServers()
+	if err != nil {
+		return nil, err
+	}
+	if n := availableTransferServers(servers); n > 0 {
+		transferDirectory.mu.Lock()
+		transferDirectory.available, transferDirectory.fetchedAt = n, time.Now()
+		transferDirectory.mu.Unlock()
+	}
+	return servers, nil
+}
+
+// availableTransferServers 返回地址、端口与信令端口齐全的服务器数量。
+func availableTransferServers(servers []g79client.TransferServer) int {
+	n := 0
+	for _, s := range servers {
+		if s.IP != "" && len(s.Ports) > 0 && s.SignalWebPort.Int64() != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TransferServerList() ([]string, []string, error) {
+	servers, err := transferServers()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,270 @@
+// Package tankey 统一生成与校验本地联机（Tan Lobby）所需的密钥材料。
+//
+// TanLobbyLogin 与 TanLobbyCreate 共用这里的实现，客户端实现方也可以用
+// vectors.go 中公开的测试向量核对自己的推导结果。
+//
+// 推导规则（AES 均为 ECB 模式，明文恰为一个分组，密文长度由 Format 决定）：
+//   - RaknetRand      = 16 字节随机数
+//   - RaknetAESRand   = AES(key=EncryptedToken, RaknetRand)
+//   - EncryptKeyBytes = EncryptedToken || RaknetRand
+//   - DecryptKeyBytes = RaknetRand || EncryptedToken
+//   - SignalingSeed   = 16 字节随机数
+//   - SignalingTicket = AES(key=UserToken, SignalingSeed)
+package tankey
+
+import (
+	"bytes"
+	"crypto/aes"
+	cryptoRand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RandSize 为 RaknetRand 与 SignalingSeed 的长度。
+const RandSize = 16
+
+// Format 为 RaknetAESRand 与 SignalingTicket 的密文格式。
+//
+// 两个接口沿用各自原有的输出长度：TanLobbyCreate 为 FormatBlock，TanLobbyLogin 为 FormatPadded。
+type Format int
+
+const (
+	// FormatBlock 只保留首个分组，共 16 字节。
+	FormatBlock Format = iota
+	// FormatPadded 为 PKCS#7 填充后的完整密文，即首个分组之后再加一个填充分组，共 32 字节。
+	FormatPadded
+)
+
+// String 返回格式名称。
+func (f Format) String() string {
+	switch f {
+	case FormatBlock:
+		return "block"
+	case FormatPadded:
+		return "padded"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+var (
+	// ErrMismatch 表示校验的密钥材料与重新推导的结果不一致。
+	ErrMismatch = errors.New("tan lobby key material mismatch")
+)
+
+// Material 为一次本地联机登录所需的全部密钥材料。
+type Material struct {
+	RaknetRand      []byte
+	RaknetAESRand   []byte
+	EncryptKeyBytes []byte
+	DecryptKeyBytes []byte
+	SignalingSeed   []byte
+	SignalingTicket []byte
+}
+
+// Generator 从随机源生成密钥材料。
+type Generator struct {
+	rand io.Reader
+}
+
+// New 创建 Generator；src 为 nil 时使用 crypto/rand。
+//
+// 传入 NewSeedSource 返回的随机源即可得到可复现的结果。
+func New(src io.Reader) *Generator {
+	if src == nil {
+		src = cryptoRand.Reader
+	}
+	return &Generator{rand: src}
+}
+
+// Generate 依次读取 RaknetRand 与 SignalingSeed 并按 format 推导其余字段。
+func (g *Generator) Generate(userToken string, encryptedToken []byte, format Format) (Material, error) {
+	raknetRand := make([]byte, RandSize)
+	if _, err := io.ReadFull(g.rand, raknetRand); err != nil {
+		return Material{}, fmt.Errorf("rand read: %w", err)
+	}
+	seed := make([]byte, RandSize)
+	if _, err := io.ReadFull(g.rand, seed); err != nil {
+		return Material{}, fmt.Errorf("rand read: %w", err)
+	}
+	return Derive(userToken, encryptedToken, raknetRand, seed, format)
+}
+
+// Derive 由给定的 RaknetRand 与 SignalingSeed 按 format 推导完整的密钥材料。
+func Derive(userToken string, encryptedToken, raknetRand, seed []byte, format Format) (Material, error) {
+	if len(raknetRand) != RandSize {
+		return Material{}, fmt.Errorf("raknet rand must be %d bytes, got %d", RandSize, len(raknetRand))
+	}
+	if len(seed) != RandSize {
+		return Material{}, fmt.Errorf("signaling seed must be %d bytes, got %d", RandSize, len(seed))
+	}
+	raknetAESRand, err := encrypt(raknetRand, encryptedToken, format)
+	if err != nil {
+		return Material{}, fmt.Errorf("aes encrypt raknet rand: %w", err)
+	}
+	ticket, err := EncryptTicket(seed, userToken, format)
+	if err != nil {
+		return Material{}, err
+	}
+	return Material{
+		RaknetRand:      bytes.Clone(raknetRand),
+		RaknetAESRand:   raknetAESRand,
+		EncryptKeyBytes: concat(encryptedToken, raknetRand),
+		DecryptKeyBytes: concat(raknetRand, encryptedToken),
+		SignalingSeed:   bytes.Clone(seed),
+		SignalingTicket: ticket,
+	}, nil
+}
+
+// EncryptTicket 由 SignalingSeed 与 UserToken 按 format 计算 SignalingTicket。
+func EncryptTicket(seed []byte, userToken string, format Format) ([]byte, error) {
+	ticket, err := encrypt(seed, []byte(userToken), format)
+	if err != nil {
+		return nil, fmt.Errorf("aes encrypt signaling seed: %w", err)
+	}
+	return ticket, nil
+}
+
+// DecryptRaknetRand 按 format 由 RaknetAESRand 还原 RaknetRand。
+func DecryptRaknetRand(raknetAESRand, encryptedToken []byte, format Format) ([]byte, error) {
+	return decrypt(raknetAESRand, encryptedToken, format)
+}
+
+// DecryptSignalingSeed 按 format 由 SignalingTicket 还原 SignalingSeed。
+func DecryptSignalingSeed(ticket []byte, userToken string, format Format) ([]byte, error) {
+	return decrypt(ticket, []byte(userToken), format)
+}
+
+// Verify 校验 m 中的每个字段是否与由 userToken/encryptedToken 按 format 推导出的结果完全一致，
+// 密文长度与 format 不符时同样返回 ErrMismatch。
+func Verify(m Material, userToken string, encryptedToken []byte, format Format) error {
+	raknetRand, err := DecryptRaknetRand(m.RaknetAESRand, encryptedToken, format)
+	if err != nil {
+		return fmt.Errorf("%w: raknet_aes_rand: %v", ErrMismatch, err)
+	}
+	seed, err := DecryptSignalingSeed(m.SignalingTicket, userToken, format)
+	if err != nil {
+		return fmt.Errorf("%w: signaling_ticket: %v", ErrMismatch, err)
+	}
+	want, err := Derive(userToken, encryptedToken, raknetRand, seed, format)
+	if err != nil {
+		return err
+	}
+	return compare(m, want)
+}
+
+func compare(got, want Material) error {
+	fields := []struct {
+		name      string
+		got, want []byte
+	}{
+		{"raknet_rand", got.RaknetRand, want.RaknetRand},
+		{"raknet_aes_rand", got.RaknetAESRand, want.RaknetAESRand},
+		{"encrypt_key_bytes", got.EncryptKeyBytes, want.EncryptKeyBytes},
+		{"decrypt_key_bytes", got.DecryptKeyBytes, want.DecryptKeyBytes},
+		{"signaling_seed", got.SignalingSeed, want.SignalingSeed},
+		{"signaling_ticket", got.SignalingTicket, want.SignalingTicket},
+	}
+	for _, f := range fields {
+		if !bytes.Equal(f.got, f.want) {
+			return fmt.Errorf("%w: %s", ErrMismatch, f.name)
+		}
+	}
+	return nil
+}
+
+// NewSeedSource 返回由 seed 确定的伪随机字节流（SHA-256 计数器模式），仅用于测试与复现。
+func NewSeedSource(seed []byte) io.Reader {
+	return &seedSource{seed: bytes.Clone(seed)}
+}
+
+type seedSource struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (s *seedSource) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], s.counter)
+			s.counter++
+			sum := sha256.Sum256(append(bytes.Clone(s.seed), ctr[:]...))
+			s.buf = sum[:]
+		}
+		c := copy(p[n:], s.buf)
+		s.buf = s.buf[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// paddingBlock 为一个分组明文经 PKCS#7 填充后追加的完整填充分组。
+var paddingBlock = bytes.Repeat([]byte{aes.BlockSize}, aes.BlockSize)
+
+// cipherSize 返回 format 下一个分组明文的密文长度。
+func cipherSize(format Format) (int, error) {
+	switch format {
+	case FormatBlock:
+		return aes.BlockSize, nil
+	case FormatPadded:
+		return 2 * aes.BlockSize, nil
+	default:
+		return 0, fmt.Errorf("unknown format %v", format)
+	}
+}
+
+// encrypt 对恰为一个分组的 src 做 AES-ECB 加密。
+//
+// FormatPadded 与 utils.AesECBEncrypt（PKCS#7 填充）的结果相同，FormatBlock 为其首个分组。
+func encrypt(src, key []byte, format Format) ([]byte, error) {
+	size, err := cipherSize(format)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) != aes.BlockSize {
+		return nil, fmt.Errorf("plaintext must be %d bytes, got %d", aes.BlockSize, len(src))
+	}
+	out := make([]byte, size)
+	block.Encrypt(out, src)
+	if format == FormatPadded {
+		block.Encrypt(out[aes.BlockSize:], paddingBlock)
+	}
+	return out, nil
+}
+
+// decrypt 还原 encrypt 的明文；密文长度必须与 format 一致，FormatPadded 还会校验填充分组。
+func decrypt(src, key []byte, format Format) ([]byte, error) {
+	size, err := cipherSize(format)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) != size {
+		return nil, fmt.Errorf("%s ciphertext must be %d bytes, got %d", format, size, len(src))
+	}
+	out := make([]byte, size)
+	for i := 0; i < size; i += aes.BlockSize {
+		block.Decrypt(out[i:i+aes.BlockSize], src[i:i+aes.BlockSize])
+	}
+	if format == FormatPadded && !bytes.Equal(out[aes.BlockSize:], paddingBlock) {
+		return nil, errors.New("bad padding")
+	}
+	return out[:aes.BlockSize], nil
+}
+
+func concat(a, b []byte) []byte {
+	return append(append(make([]byte, 0, len(a)+len(b)), a...), b...)
+}
@@ -0,0 +1,88 @@
+package tankey
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+	for _, v := range Vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			if err := v.Check(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func generate(t *testing.T, v Vector, format Format) (Material, []byte) {
+	t.Helper()
+	encryptedToken, err := hex.DecodeString(v.EncryptedTokenHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(NewSeedSource([]byte(v.Seed))).Generate(v.UserToken, encryptedToken, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, encryptedToken
+}
+
+func TestPaddedExtendsBlock(t *testing.T) {
+	v := Vectors[0]
+	block, _ := generate(t, v, FormatBlock)
+	padded, _ := generate(t, v, FormatPadded)
+	if len(block.SignalingTicket) != 16 || len(padded.SignalingTicket) != 32 {
+		t.Fatalf("ticket lengths = %d, %d; want 16, 32", len(block.SignalingTicket), len(padded.SignalingTicket))
+	}
+	if !bytes.Equal(padded.RaknetAESRand[:16], block.RaknetAESRand) || !bytes.Equal(padded.SignalingTicket[:16], block.SignalingTicket) {
+		t.Fatal("first block of the padded ciphertext differs from the block format")
+	}
+}
+
+func TestVerifyFormat(t *testing.T) {
+	v := Vectors[0]
+	block, encryptedToken := generate(t, v, FormatBlock)
+	padded, _ := generate(t, v, FormatPadded)
+
+	truncated := padded
+	truncated.RaknetAESRand = padded.RaknetAESRand[:16]
+	truncated.SignalingTicket = padded.SignalingTicket[:16]
+	badPadding := padded
+	badPadding.SignalingTicket = bytes.Clone(padded.SignalingTicket)
+	badPadding.SignalingTicket[31] ^= 1
+	tampered := padded
+	tampered.EncryptKeyBytes = bytes.Clone(padded.EncryptKeyBytes)
+	tampered.EncryptKeyBytes[0] ^= 1
+
+	tests := []struct {
+		name    string
+		m       Material
+		format  Format
+		wantErr bool
+	}{
+		{"block", block, FormatBlock, false},
+		{"padded", padded, FormatPadded, false},
+		{"padded as block", padded, FormatBlock, true},
+		{"block as padded", block, FormatPadded, true},
+		{"truncated padded", truncated, FormatPadded, true},
+		{"bad padding", badPadding, FormatPadded, true},
+		{"tampered", tampered, FormatPadded, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.m, v.UserToken, encryptedToken, tt.format)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Verify = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrMismatch) {
+				t.Fatalf("Verify = %v, want ErrMismatch", err)
+			}
+		})
+	}
+}
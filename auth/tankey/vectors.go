@@ -0,0 +1,122 @@
+package tankey
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+// Vector 为一组公开的测试向量：以 Seed 构造 NewSeedSource 后调用 Generate，
+// 应得到与各 hex 字段一致的结果（*PaddedHex 为 FormatPadded 下的密文）。
+// EncryptKeyBytes/DecryptKeyBytes 可由拼接规则直接得到，故不单独列出。
+type Vector struct {
+	Name              string
+	UserToken         string
+	EncryptedTokenHex string
+	Seed              string
+
+	RaknetRandHex      string
+	RaknetAESRandHex   string
+	SignalingSeedHex   string
+	SignalingTicketHex string
+
+	RaknetAESRandPaddedHex   string
+	SignalingTicketPaddedHex string
+}
+
+// Vectors 为公开的测试向量，内容同步记录在 docs/TANLOBBY_KEYS.md。
+var Vectors = []Vector{
+	{
+		Name:               "basic",
+		UserToken:          "0123456789abcdef0123456789abcdef",
+		EncryptedTokenHex:  "000102030405060708090a0b0c0d0e0f",
+		Seed:               "funauth-tankey-vector-1",
+		RaknetRandHex:      "c67808e6b0ec4e802095e49169da3795",
+		RaknetAESRandHex:   "30b843e1702addd4a9dd871e1388f054",
+		SignalingSeedHex:   "5033374b55ef3c311d989b3e5feb6728",
+		SignalingTicketHex: "43684fb3298fb62e24e02d1d121b30af",
+
+		RaknetAESRandPaddedHex:   "30b843e1702addd4a9dd871e1388f054954f64f2e4e86e9eee82d20216684899",
+		SignalingTicketPaddedHex: "43684fb3298fb62e24e02d1d121b30af8aa36241fde8df054dc325c6c695b89e",
+	},
+	{
+		Name:               "aes256-encrypted-token",
+		UserToken:          "AbCdEfGhIjKlMnOpQrStUvWxYz012345",
+		EncryptedTokenHex:  "f0e1d2c3b4a5968778695a4b3c2d1e0f00112233445566778899aabbccddeeff",
+		Seed:               "funauth-tankey-vector-2",
+		RaknetRandHex:      "69281964599f23e7d42127852a25b61d",
+		RaknetAESRandHex:   "8a3c7bb1e9dc3fe50e7d565656bb377a",
+		SignalingSeedHex:   "f3b8686eec5127eb806140b15ac332a4",
+		SignalingTicketHex: "8552db4830f25f2c4b8f7926901e8ba3",
+
+		RaknetAESRandPaddedHex:   "8a3c7bb1e9dc3fe50e7d565656bb377a54c23c84ceb99a3237f43ba464293e11",
+		SignalingTicketPaddedHex: "8552db4830f25f2c4b8f7926901e8ba311c6a221538f3f342b37975a5343e8e0",
+	},
+	{
+		Name:               "aes128-user-token",
+		UserToken:          "tokentokentoken!",
+		EncryptedTokenHex:  "5f4dcc3b5aa765d61d8327deb882cf99",
+		Seed:               "funauth-tankey-vector-3",
+		RaknetRandHex:      "fb204384b19a2d414927d747de4f6144",
+		RaknetAESRandHex:   "bb4314406e69c754194bccda00d6b5b8",
+		SignalingSeedHex:   "adb336296bcb0b48804b980c13e7def7",
+		SignalingTicketHex: "3f534e2d809eba22d65cc4cb75a249c7",
+
+		RaknetAESRandPaddedHex:   "bb4314406e69c754194bccda00d6b5b8a9ddd2d8268c6210aec91b652bf8bc18",
+		SignalingTicketPaddedHex: "3f534e2d809eba22d65cc4cb75a249c7a101718ca62085d48de468489da8f92e",
+	},
+}
+
+// Check 按向量以两种格式重新生成并校验密钥材料。
+func (v Vector) Check() error {
+	for _, format := range []Format{FormatBlock, FormatPadded} {
+		if err := v.check(format); err != nil {
+			return fmt.Errorf("%s/%s: %w", v.Name, format, err)
+		}
+	}
+	return nil
+}
+
+func (v Vector) check(format Format) error {
+	encryptedToken, err := hex.DecodeString(v.EncryptedTokenHex)
+	if err != nil {
+		return fmt.Errorf("decode encrypted token: %w", err)
+	}
+	m, err := New(NewSeedSource([]byte(v.Seed))).Generate(v.UserToken, encryptedToken, format)
+	if err != nil {
+		return err
+	}
+	raknetAESRand, ticket := v.RaknetAESRandHex, v.SignalingTicketHex
+	if format == FormatPadded {
+		raknetAESRand, ticket = v.RaknetAESRandPaddedHex, v.SignalingTicketPaddedHex
+	}
+	fields := []struct {
+		name, want string
+		got        []byte
+	}{
+		{"raknet_rand", v.RaknetRandHex, m.RaknetRand},
+		{"raknet_aes_rand", raknetAESRand, m.RaknetAESRand},
+		{"signaling_seed", v.SignalingSeedHex, m.SignalingSeed},
+		{"signaling_ticket", ticket, m.SignalingTicket},
+	}
+	for _, f := range fields {
+		want, err := hex.DecodeString(f.want)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", f.name, err)
+		}
+		if !bytes.Equal(f.got, want) {
+			return fmt.Errorf("%w: %s = %x, want %s", ErrMismatch, f.name, f.got, f.want)
+		}
+	}
+	return Verify(m, v.UserToken, encryptedToken, format)
+}
+
+// CheckVectors 校验全部公开向量，供 `funauth tanlobby vectors` 使用。
+func CheckVectors() error {
+	for _, v := range Vectors {
+		if err := v.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
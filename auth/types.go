@@ -64,3 +64,10 @@ type TanLobbyCreateResult struct {
 	SignalingSeed          []byte
 	SignalingTicket        []byte
 }
+
+// TransferStartTypeResult 为 TransferStartTypeDetail 的中间结果，便于调试。
+type TransferStartTypeResult struct {
+	Plaintext string
+	Merged    string
+	Encrypted string
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+// runCheckNumWorker 作为常驻 worker 运行：stdin/stdout 用于按行协议，日志改写到 stderr。
+func runCheckNumWorker(args []string) int {
+	log.SetOutput(os.Stderr)
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: funauth checknum-worker")
+		return 2
+	}
+	// worker 由服务进程启动并继承其环境，按同一个 FUNAUTH_CONFIG 读取 python3 等配置
+	cfg, err := config.Load(strings.TrimSpace(os.Getenv("FUNAUTH_CONFIG")))
+	if err != nil {
+		log.Printf("[checknum] worker config: %v", err)
+		return 1
+	}
+	auth.Configure(cfg.AuthOptions())
+	if err := auth.ServeCheckNumWorker(os.Stdin, os.Stdout); err != nil {
+		log.Printf("[checknum] worker stopped: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: funauth config check [-config file]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("FUNAUTH_CONFIG"), "config file (.yaml, .yml or .toml)")
+	quiet := fs.Bool("q", false, "only report errors")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(strings.TrimSpace(*path))
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config check: %v\n", err)
+		return 1
+	}
+	if *quiet {
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tENV\tVALUE\tRELOAD")
+	for _, s := range cfg.Settings() {
+		reload := ""
+		if s.Reload {
+			reload = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Path, s.Env, s.Value, reload)
+	}
+	tw.Flush()
+	if n := len(cfg.Tenants.List); n > 0 {
+		fmt.Printf("%d inline tenants\n", n)
+	}
+	if cfg.Skin.DefaultItemID != "" {
+		fmt.Printf("skin.default_item_id = %s\n", cfg.Skin.DefaultItemID)
+	}
+	fmt.Println("config OK")
+	return 0
+}
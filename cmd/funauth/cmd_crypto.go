@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Yeah114/FunAuth/auth"
+)
+
+func runCryptoCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: funauth crypto encrypt|decrypt [-in file|-] [-pretty] [text]")
+		return 2
+	}
+	mode := args[0]
+	if mode != "encrypt" && mode != "decrypt" {
+		fmt.Fprintf(os.Stderr, "unknown crypto command %q\n", mode)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("crypto "+mode, flag.ContinueOnError)
+	in := fs.String("in", "", "read input from file (- for stdin) instead of the argument")
+	pretty := fs.Bool("pretty", false, "pretty-print decrypted JSON payloads")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	var input string
+	switch {
+	case *in == "-":
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "crypto %s: read stdin: %v\n", mode, err)
+			return 1
+		}
+		input = string(raw)
+	case *in != "":
+		raw, err := os.ReadFile(*in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "crypto %s: read input: %v\n", mode, err)
+			return 1
+		}
+		input = string(raw)
+	case fs.NArg() > 0:
+		input = strings.Join(fs.Args(), " ")
+	default:
+		fmt.Fprintf(os.Stderr, "crypto %s: missing input\n", mode)
+		return 2
+	}
+
+	if mode == "encrypt" {
+		enc, err := auth.G79Encrypt(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "crypto encrypt: %v\n", err)
+			return 1
+		}
+		fmt.Println(enc)
+		return 0
+	}
+
+	plain, err := auth.G79Decrypt(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "crypto decrypt: %v\n", err)
+		return 1
+	}
+	if *pretty {
+		plain = auth.PrettyPayload(plain)
+	}
+	fmt.Println(plain)
+	return 0
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+func runMCPCommand(args []string) int {
+	if len(args) == 0 || args[0] != "decompile" {
+		fmt.Fprintln(os.Stderr, "usage: funauth mcp decompile [flags]")
+		return 2
+	}
+	return runMCPDecompile(args[1:])
+}
+
+func runMCPDecompile(args []string) int {
+	fs := flag.NewFlagSet("mcp decompile", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("FUNAUTH_CONFIG"), "config file (.yaml, .yml or .toml)")
+	in := fs.String("in", "-", "input file: raw mcp, hex text or transfer_check_num data (- for stdin)")
+	format := fs.String("format", "auto", "input format: auto (guess from content), raw, hex or data")
+	hexStr := fs.String("hex", "", "mcp as hex string (overrides -in and -format)")
+	out := fs.String("out", "-", "output file (- for stdout)")
+	timeout := fs.Duration("timeout", 60*time.Second, "decompile timeout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(strings.TrimSpace(*path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: %v\n", err)
+		return 1
+	}
+	auth.Configure(cfg.AuthOptions())
+
+	inputFormat, err := auth.ParseMCPInputFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: %v\n", err)
+		return 2
+	}
+	var raw []byte
+	switch {
+	case *hexStr != "":
+		raw, inputFormat = []byte(*hexStr), auth.MCPInputHex
+	case *in == "-":
+		raw, err = io.ReadAll(os.Stdin)
+	default:
+		raw, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: read input: %v\n", err)
+		return 1
+	}
+	mcp, err := auth.DecodeMCPInput(raw, inputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	text, err := auth.DecompileMCP(ctx, mcp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: %v\n", err)
+		return 1
+	}
+
+	if *out == "-" {
+		fmt.Println(text)
+		return 0
+	}
+	if err := os.WriteFile(*out, []byte(text), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp decompile: write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/config"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+)
+
+// maxSampleDump 为解析失败时输出的响应体长度上限。
+const maxSampleDump = 2048
+
+func runProxyCommand(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintln(os.Stderr, "usage: funauth proxy test [-config file] [-file sample|-] [-url api_url]")
+		return 2
+	}
+	fs := flag.NewFlagSet("proxy test", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("FUNAUTH_CONFIG"), "config file (.yaml, .yml or .toml)")
+	file := fs.String("file", "", "read a sample proxy pool response from file (- for stdin) instead of requesting the api")
+	apiURL := fs.String("url", "", "proxy pool api url, defaults to proxy.api_url")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(strings.TrimSpace(*path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "proxy test: %v\n", err)
+		return 1
+	}
+	if err := proxy.Configure(cfg.Proxy.Options()); err != nil {
+		fmt.Fprintf(os.Stderr, "proxy test: %v\n", err)
+		return 1
+	}
+
+	m := cfg.Proxy.Fields.Mapping().WithDefaults()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tPATH")
+	for _, f := range [][2]string{
+		{"records", m.Records}, {"ip", m.IP}, {"port", m.Port}, {"username", m.Username}, {"password", m.Password},
+		{"expiry", m.Expiry}, {"code", m.Code}, {"success_codes", m.SuccessCodes}, {"message", m.Message},
+	} {
+		if f[1] == "" {
+			f[1] = "(auto)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", f[0], f[1])
+	}
+	tw.Flush()
+	fmt.Println()
+
+	var body []byte
+	switch {
+	case *file == "-":
+		body, err = io.ReadAll(os.Stdin)
+	case *file != "":
+		body, err = os.ReadFile(*file)
+	case *apiURL == "" && cfg.Proxy.APIURL == "" && cfg.Proxy.ListFile != "":
+		return printProxies(proxy.LoadList(cfg.Proxy.ListFile, schemeOrDefault(cfg.Proxy.Scheme)))
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		body, err = proxy.FetchResponse(ctx, strings.TrimSpace(*apiURL))
+		if errors.Is(err, proxy.ErrProxyDisabled) {
+			err = errors.New("no proxy source configured, use -file or -url")
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "proxy test: %v\n", err)
+		dumpSample(body)
+		return 1
+	}
+
+	list, err := proxy.ParseResponse(body)
+	if code := printProxies(list, err); code != 0 {
+		dumpSample(body)
+		return code
+	}
+	return 0
+}
+
+func printProxies(list []*proxy.Proxy, err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "proxy test: %v\n", err)
+		return 1
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROXY\tEXPIRES")
+	for _, px := range list {
+		expires := "-"
+		if !px.ExpiresAt.IsZero() {
+			expires = px.ExpiresAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", px.URL.Redacted(), expires)
+	}
+	tw.Flush()
+	fmt.Printf("%d proxies\n", len(list))
+	return 0
+}
+
+// dumpSample 把响应体输出到 stderr，便于对照字段映射。
+func dumpSample(body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if len(body) > maxSampleDump {
+		body = append(body[:maxSampleDump:maxSampleDump], "..."...)
+	}
+	fmt.Fprintf(os.Stderr, "response:\n%s\n", body)
+}
+
+func schemeOrDefault(scheme string) string {
+	if scheme == "" {
+		return "http"
+	}
+	return scheme
+}
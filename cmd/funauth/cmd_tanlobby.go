@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Yeah114/FunAuth/auth/tankey"
+)
+
+func runTanLobbyCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: funauth tanlobby vectors [flags]")
+		return 2
+	}
+	switch args[0] {
+	case "vectors":
+		return runTanLobbyVectors(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown tanlobby command %q\n", args[0])
+		return 2
+	}
+}
+
+func runTanLobbyVectors(args []string) int {
+	fs := flag.NewFlagSet("tanlobby vectors", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "print every vector")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *verbose {
+		for _, v := range tankey.Vectors {
+			fmt.Printf("%s\n  user_token=%s encrypted_token=%s seed=%q\n  raknet_rand=%s raknet_aes_rand=%s\n  signaling_seed=%s signaling_ticket=%s\n  padded: raknet_aes_rand=%s signaling_ticket=%s\n",
+				v.Name, v.UserToken, v.EncryptedTokenHex, v.Seed,
+				v.RaknetRandHex, v.RaknetAESRandHex, v.SignalingSeedHex, v.SignalingTicketHex,
+				v.RaknetAESRandPaddedHex, v.SignalingTicketPaddedHex)
+		}
+	}
+	if err := tankey.CheckVectors(); err != nil {
+		fmt.Fprintf(os.Stderr, "tanlobby vectors: %v\n", err)
+		return 1
+	}
+	fmt.Printf("%d vectors ok\n", len(tankey.Vectors))
+	return 0
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command 为一个 funauth 子命令，run 返回进程退出码。
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+var commands = map[string]command{
+	"checknum-worker": {usage: "checknum-worker (internal, started by the check-num worker pool)", run: runCheckNumWorker},
+	"config":          {usage: "config check [-config file] [-q]", run: runConfigCommand},
+	"crypto":          {usage: "crypto encrypt|decrypt [-in file|-] [-pretty] [text]", run: runCryptoCommand},
+	"mcp":             {usage: "mcp decompile [-config file] [-in file|-hex hex] [-format auto|raw|hex|data] [-out file]", run: runMCPCommand},
+	"proxy":           {usage: "proxy test [-config file] [-file sample|-] [-url api_url]", run: runProxyCommand},
+	"tanlobby":        {usage: "tanlobby vectors [flags]", run: runTanLobbyCommand},
+}
+
+// runCommand 处理子命令；args 为空或首个参数为 flag 时返回 false，由调用方启动 HTTP 服务。
+func runCommand(args []string) (int, bool) {
+	if len(args) == 0 || (len(args[0]) > 0 && args[0][0] == '-') {
+		return 0, false
+	}
+	name := args[0]
+	if name == "help" {
+		printUsage()
+		return 0, true
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		printUsage()
+		return 2, true
+	}
+	return cmd.run(args[1:]), true
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: funauth [command]")
+	fmt.Fprintln(os.Stderr, "without a command the HTTP server is started")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
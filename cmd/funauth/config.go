@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yeah114/FunAuth/cmd/funauth/internal/handlers"
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+// configWatchInterval 为检查配置文件是否变化的间隔。
+const configWatchInterval = 5 * time.Second
+
+var (
+	configMu      sync.Mutex
+	configPath    string
+	configStarted *config.Config // 启动时的配置，用于判断哪些修改需要重启
+)
+
+// loadConfig 读取 FUNAUTH_CONFIG 指定的配置文件（未设置时只使用环境变量），校验失败时退出进程。
+func loadConfig() *config.Config {
+	configPath = strings.TrimSpace(os.Getenv("FUNAUTH_CONFIG"))
+	cfg, err := config.Load(configPath)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Fatalf("[config] invalid configuration:\n%v", err)
+	}
+	if err := handlers.Setup(cfg); err != nil {
+		log.Fatalf("[config] %v", err)
+	}
+	configStarted = cfg
+	if configPath != "" {
+		log.Printf("[config] loaded %s (%s)", configPath, cfg)
+	}
+	return cfg
+}
+
+// watchConfig 在收到 SIGHUP 或配置文件变化时重新加载可热更新的配置。
+func watchConfig() {
+	if configPath == "" {
+		return
+	}
+	hup := make(chan os.Signal, 1)
+	if notifyReload(hup) {
+		go func() {
+			for range hup {
+				reloadConfig("SIGHUP")
+			}
+		}()
+	}
+	go config.Watch(context.Background(), configPath, configWatchInterval, func() {
+		reloadConfig("file change")
+	})
+}
+
+// reloadConfig 重新读取配置文件；校验失败时保留当前配置。
+func reloadConfig(reason string) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	cfg, err := config.Load(configPath)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Printf("[config] reload (%s) rejected, keeping current configuration:\n%v", reason, err)
+		return
+	}
+	if changed := cfg.RestartRequired(configStarted); len(changed) > 0 {
+		log.Printf("[config] restart required to apply: %s", strings.Join(changed, ", "))
+	}
+	if err := handlers.ApplyConfig(cfg); err != nil {
+		log.Printf("[config] reload (%s) failed: %v", reason, err)
+		return
+	}
+	log.Printf("[config] reloaded (%s)", reason)
+}
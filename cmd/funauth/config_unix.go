@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReload 将 SIGHUP 转发到 ch。
+func notifyReload(ch chan<- os.Signal) bool {
+	signal.Notify(ch, syscall.SIGHUP)
+	return true
+}
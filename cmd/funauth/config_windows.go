@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// notifyReload 在 Windows 下没有 SIGHUP，只依赖配置文件变化触发重新加载。
+func notifyReload(ch chan<- os.Signal) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+const adminTokenHeader = "X-FunAuth-Admin-Token"
+
+// adminOnly 要求请求头携带与 expected 一致的管理员令牌；
+// 未配置管理员令牌时管理接口一律拒绝。
+func adminOnly(expected string) gin.HandlerFunc {
+	expected = strings.TrimSpace(expected)
+	return func(c *gin.Context) {
+		if expected == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "message": "admin api disabled"})
+			return
+		}
+		got := strings.TrimSpace(c.GetHeader(adminTokenHeader))
+		if subtle.ConstantTimeCompare([]byte(got), []byte(expected)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "message": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// 汇总注册仅限管理员访问的接口，cfg.Token 为管理员令牌
+func RegisterAdminRoutes(api *gin.RouterGroup, cfg config.Admin) {
+	admin := api.Group("/admin", adminOnly(cfg.Token))
+	RegisterAdminCryptoRoutes(admin)
+	RegisterAdminAuditRoutes(admin)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAdminCryptoRoutes 注册 G79 HTTP 载荷的加解密调试接口。
+func RegisterAdminCryptoRoutes(admin *gin.RouterGroup) {
+	admin.POST("/crypto/encrypt", func(c *gin.Context) {
+		var req CryptoEncryptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, CryptoResponse{Success: false, Message: fmt.Sprintf("CryptoEncrypt: 绑定请求体时出现问题, 原因是 %v", err)})
+			return
+		}
+		enc, err := auth.G79Encrypt(req.Plaintext)
+		if err != nil {
+			c.JSON(http.StatusOK, CryptoResponse{Success: false, Message: fmt.Sprintf("CryptoEncrypt: %v", err)})
+			return
+		}
+		c.JSON(http.StatusOK, CryptoResponse{Success: true, Message: "ok", Content: enc})
+	})
+
+	admin.POST("/crypto/decrypt", func(c *gin.Context) {
+		var req CryptoDecryptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, CryptoResponse{Success: false, Message: fmt.Sprintf("CryptoDecrypt: 绑定请求体时出现问题, 原因是 %v", err)})
+			return
+		}
+		plain, err := auth.G79Decrypt(req.Content)
+		if err != nil {
+			c.JSON(http.StatusOK, CryptoResponse{Success: false, Message: fmt.Sprintf("CryptoDecrypt: %v", err)})
+			return
+		}
+		resp := CryptoResponse{Success: true, Message: "ok", Plaintext: plain}
+		if req.Pretty {
+			resp.Pretty = auth.PrettyPayload(plain)
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+}
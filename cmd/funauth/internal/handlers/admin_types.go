@@ -0,0 +1,40 @@
+package handlers
+
+import "github.com/Yeah114/FunAuth/internal/audit"
+
+// CryptoEncryptRequest ..
+type CryptoEncryptRequest struct {
+	Plaintext string `json:"plaintext"`
+}
+
+// CryptoDecryptRequest ..
+type CryptoDecryptRequest struct {
+	Content string `json:"content"`
+	Pretty  bool   `json:"pretty,omitempty"`
+}
+
+// CryptoResponse ..
+type CryptoResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Content   string `json:"content,omitempty"`
+	Plaintext string `json:"plaintext,omitempty"`
+	Pretty    string `json:"pretty,omitempty"`
+}
+
+// AuditQuery ..
+type AuditQuery struct {
+	From    string `form:"from"`
+	To      string `form:"to"`
+	Account string `form:"account"`
+	Outcome string `form:"outcome"`
+	Event   string `form:"event"`
+	Limit   int    `form:"limit"`
+}
+
+// AuditQueryResponse ..
+type AuditQueryResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Events  []audit.Event `json:"events,omitempty"`
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/config"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+)
+
+// skinItemID 为账号未设置皮肤时使用的皮肤，由 Setup 设置。
+var skinItemID = auth.DefaultSkinItemID
+
+// Setup 按启动时的配置设置 auth 包的运行参数，创建会话存储、令牌签发器与审计日志，再应用可热更新的配置。
+// 在 router.NewRouter 之前调用一次；这些配置修改后需要重启。
+func Setup(cfg *config.Config) error {
+	auth.Configure(cfg.AuthOptions())
+	skinItemID = cfg.Skin.ItemID()
+	setupSessions(cfg.Session.StoreConfig())
+	if err := setupTokens(cfg.Token.Options()); err != nil {
+		return err
+	}
+	setupAudit(cfg.Audit.LoggerConfig())
+	return ApplyConfig(cfg)
+}
+
+// ApplyConfig 应用可在运行时修改的配置：租户、令牌签名密钥、请求限流、账号登录限流、代理池与就绪检查。
+// 由 Setup 以及每次重新加载配置后调用。
+func ApplyConfig(cfg *config.Config) error {
+	list, err := cfg.TenantList()
+	if err != nil {
+		return err
+	}
+	if err := applyTenants(list); err != nil {
+		return err
+	}
+	if err := applyTokenKeys(cfg.Token.Keys); err != nil {
+		return err
+	}
+	reloadRequestLimiters(cfg.RateLimit)
+	auth.ReloadLoginLimiter(cfg.RateLimit.LoginLimit())
+	if err := proxy.Configure(cfg.Proxy.Options()); err != nil {
+		return err
+	}
+	transferDirectoryMaxAge.Store(int64(time.Duration(cfg.Health.TransferMaxAge)))
+	return nil
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 服务关闭时的请求排空状态。
+var (
+	draining atomic.Bool
+
+	inflightMu  sync.Mutex
+	inflightSeq uint64
+	inflight    = make(map[uint64]inflightRequest)
+)
+
+type inflightRequest struct {
+	method   string
+	path     string
+	clientIP string
+	start    time.Time
+}
+
+// Drain 跟踪进行中的请求；BeginDrain 之后新到达的请求直接返回 503，
+// 同时 /readyz 也返回 503，使负载均衡摘除本实例。
+func Drain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if draining.Load() {
+			c.Header("Connection", "close")
+			c.Header("Retry-After", "5")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"success": false, "message": "server is shutting down"})
+			return
+		}
+		inflightMu.Lock()
+		inflightSeq++
+		id := inflightSeq
+		inflight[id] = inflightRequest{method: c.Request.Method, path: c.Request.URL.Path, clientIP: c.ClientIP(), start: time.Now()}
+		inflightMu.Unlock()
+		defer func() {
+			inflightMu.Lock()
+			delete(inflight, id)
+			inflightMu.Unlock()
+		}()
+		c.Next()
+	}
+}
+
+// BeginDrain 开始排空：此后的新请求返回 503。
+func BeginDrain() { draining.Store(true) }
+
+// Close 在 HTTP 服务停止后关闭已打开的会话存储（文件后端会压缩日志）与审计日志。
+func Close() error {
+	var errs []error
+	if sessionStore != nil {
+		errs = append(errs, sessionStore.Close())
+	}
+	if auditLogger != nil {
+		errs = append(errs, auditLogger.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// InFlight 返回进行中的请求描述，按开始时间排序。
+func InFlight() []string {
+	inflightMu.Lock()
+	list := make([]inflightRequest, 0, len(inflight))
+	for _, r := range inflight {
+		list = append(list, r)
+	}
+	inflightMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+
+	out := make([]string, len(list))
+	for i, r := range list {
+		out[i] = fmt.Sprintf("%s %s from %s (running %s)", r.method, r.path, r.clientIP, time.Since(r.start).Round(time.Millisecond))
+	}
+	return out
+}
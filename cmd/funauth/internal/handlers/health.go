@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/health"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+)
+
+const defaultTransferDirectoryMaxAge = 10 * time.Minute
+
+var (
+	startedAt = time.Now()
+
+	readinessOnce sync.Once
+	readiness     *health.Checker
+)
+
+func readinessChecker() *health.Checker {
+	readinessOnce.Do(func() {
+		readiness = health.NewChecker(
+			health.Check{
+				Name: "python3",
+				Run:  auth.CheckPython,
+				TTL:  time.Minute,
+			},
+			health.Check{
+				Name: "proxy_pool",
+				Run: func(ctx context.Context) (string, error) {
+					st, err := proxy.Health()
+					if errors.Is(err, proxy.ErrProxyDisabled) {
+						return "no proxy source configured", health.ErrSkipped
+					}
+					if err != nil {
+						return "", err
+					}
+					return proxyPoolDetail(st)
+				},
+			},
+			health.Check{
+				Name: "proxy_source",
+				Run: func(ctx context.Context) (string, error) {
+					detail, err := proxy.CheckSource(ctx)
+					if errors.Is(err, proxy.ErrProxyDisabled) {
+						return "no proxy source configured", health.ErrSkipped
+					}
+					return detail, err
+				},
+				TTL: time.Minute,
+			},
+			health.Check{
+				Name: "transfer_servers",
+				Run: func(ctx context.Context) (string, error) {
+					st, err := auth.CheckTransferServers(ctx, transferMaxAge())
+					return transferDirectoryDetail(st), err
+				},
+				TTL: time.Minute,
+			},
+			health.Check{
+				Name: "fallback_cookie",
+				Run: func(ctx context.Context) (string, error) {
+					uid, err := auth.CheckCookie(ctx, fixedCookie)
+					if err != nil {
+						return "", err
+					}
+					return "user id " + uid, nil
+				},
+				// 校验 Cookie 需要一次真实的上游认证，缓存更久以免触发上游限流
+				Timeout: 20 * time.Second,
+				TTL:     10 * time.Minute,
+			},
+		)
+	})
+	return readiness
+}
+
+// transferDirectoryMaxAge 为中转服务器目录的最长使用时间（health.transfer_max_age），由 ApplyConfig 设置：
+// 重新拉取失败时，目录距最近一次成功拉取不超过该时间仍视为就绪。
+var transferDirectoryMaxAge atomic.Int64
+
+func transferMaxAge() time.Duration {
+	if d := time.Duration(transferDirectoryMaxAge.Load()); d > 0 {
+		return d
+	}
+	return defaultTransferDirectoryMaxAge
+}
+
+func transferDirectoryDetail(st auth.TransferDirectoryStatus) string {
+	if st.Available == 0 {
+		return ""
+	}
+	detail := fmt.Sprintf("%d available, fetched %s ago", st.Available, st.Age.Round(time.Second))
+	if st.RefreshErr != nil {
+		detail += fmt.Sprintf(" (refresh failed: %v)", st.RefreshErr)
+	}
+	return detail
+}
+
+// RegisterHealthRoutes 注册 GET /healthz（进程存活）与 GET /readyz（依赖检查，任一失败返回 503）。
+func RegisterHealthRoutes(r gin.IRoutes) {
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":         health.StatusOK,
+			"uptime_seconds": int64(time.Since(startedAt).Seconds()),
+		})
+	})
+	r.GET("/readyz", func(c *gin.Context) {
+		rep := readinessChecker().Run(c.Request.Context())
+		status := http.StatusOK
+		if rep.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, rep)
+	})
+}
+
+// proxyPoolDetail 根据代理池缓存与最近一次获取的结果判断其状态：
+// 有可用代理即为正常；尚未获取过时跳过（代理池在首次使用时才会获取）；否则报告最近一次获取的错误。
+func proxyPoolDetail(st proxy.PoolStatus) (string, error) {
+	switch {
+	case st.Healthy > 0:
+		return fmt.Sprintf("%d healthy", st.Healthy), nil
+	case st.LastFetchAt.IsZero():
+		return "no proxy fetched yet", health.ErrSkipped
+	case st.LastFetchErr != nil:
+		return "", fmt.Errorf("last fetch at %s: %w", st.LastFetchAt.UTC().Format(time.RFC3339), st.LastFetchErr)
+	default:
+		return "", fmt.Errorf("no healthy proxy (last fetch at %s returned %d)", st.LastFetchAt.UTC().Format(time.RFC3339), st.LastFetched)
+	}
+}
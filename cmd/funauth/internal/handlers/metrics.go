@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/config"
+	"github.com/Yeah114/FunAuth/internal/metrics"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounterVec(
+		"funauth_http_requests_total",
+		"HTTP requests by route, method and status code.",
+		"route", "method", "status")
+	httpDuration = metrics.Default.NewHistogramVec(
+		"funauth_http_request_duration_seconds",
+		"HTTP request latency by route and method.",
+		nil, "route", "method")
+	authEvents = metrics.Default.NewCounterVec(
+		"funauth_auth_events_total",
+		"Login, tan lobby and check-num outcomes by game mode and failing step.",
+		"event", "mode", "outcome", "error_kind")
+	sessionsGauge = metrics.Default.NewGaugeFunc(
+		"funauth_sessions",
+		"Sessions currently held by the session store.",
+		nil)
+)
+
+// Metrics 记录每个路由的请求数与耗时；未匹配路由的请求归入 route="unmatched"。
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.Inc(route, method, strconv.Itoa(c.Writer.Status()))
+		httpDuration.ObserveSince(start, route, method)
+	}
+}
+
+// recordMetrics 把一次审计事件计入指标。
+func (r *auditRecord) recordMetrics() {
+	authEvents.Inc(r.ev.Event, r.ev.Mode, r.ev.Outcome, r.ev.ErrorKind)
+	if r.err != nil {
+		auth.RecordErrorCode(r.err)
+	}
+}
+
+// RegisterMetricsRoute 注册 GET /metrics；配置 cfg.Token 时要求 Authorization: Bearer <token>。
+func RegisterMetricsRoute(r gin.IRoutes, cfg config.Metrics) {
+	expected := strings.TrimSpace(cfg.Token)
+	r.GET("/metrics", func(c *gin.Context) {
+		if expected != "" {
+			got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(expected)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = metrics.Default.WritePrometheus(c.Writer)
+	})
+}
@@ -1,15 +1,73 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+
+	"github.com/Yeah114/FunAuth/internal/tenant"
 )
 
+// RegisterNewRoutes 注册 GET /new：签发一个带有效期的 Bearer 令牌。
+//
+// 可选查询参数 scope（逗号分隔）限定令牌的权限范围，省略时为调用方可签发的全部范围；
+// ttl 指定有效期（不超过 FUNAUTH_TOKEN_TTL）。签发的令牌总是带有明确的权限范围。
 func RegisterNewRoutes(rg *gin.RouterGroup) {
+	issuer := tokens()
 	rg.GET("/new", func(c *gin.Context) {
-		id := uuid.NewString()
-		c.Data(http.StatusOK, "text/plain", []byte(id))
+		scopes, status, err := mintScopes(c.Query("scope"), tenantFromContext(c))
+		if err != nil {
+			c.String(status, err.Error())
+			return
+		}
+		var ttl time.Duration
+		if raw := c.Query("ttl"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				c.String(http.StatusBadRequest, "invalid ttl")
+				return
+			}
+			ttl = d
+		}
+		tok, _, err := issuer.Issue(scopes, ttl)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "text/plain", []byte(tok))
 	})
 }
+
+// mintScopes 返回调用方请求签发的权限范围及失败时建议的 HTTP 状态码：
+// 未知的范围返回 400，租户无权签发的范围返回 403；raw 为空时返回租户可签发的全部范围。
+func mintScopes(raw string, t *tenant.Tenant) ([]string, int, error) {
+	var scopes []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s == "" || slices.Contains(scopes, s) {
+			continue
+		}
+		if !slices.Contains(tokenScopes, s) {
+			return nil, http.StatusBadRequest, fmt.Errorf("unknown scope %q", s)
+		}
+		if t != nil && !t.AllowsScope(s) {
+			return nil, http.StatusForbidden, tenant.ErrScopeDenied
+		}
+		scopes = append(scopes, s)
+	}
+	if len(scopes) > 0 {
+		return scopes, http.StatusOK, nil
+	}
+	for _, s := range tokenScopes {
+		if t == nil || t.AllowsScope(s) {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, http.StatusForbidden, tenant.ErrScopeDenied
+	}
+	return scopes, http.StatusOK, nil
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/Yeah114/FunAuth/internal/lru"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+)
+
+// cookieAccountCacheSize 为记住的 Cookie 数量上限。
+const cookieAccountCacheSize = 4096
+
+// cookieAccounts 记录 Cookie 的 SHA-256 到账号 uid 的映射。
+// 账号 uid 要在 Cookie 认证后才能得知，命中时 Cookie 认证本身即可使用该账号的固定代理。
+var cookieAccounts = lru.New(cookieAccountCacheSize)
+
+func cookieKey(cookie string) string {
+	sum := sha256.Sum256([]byte(cookie))
+	return hex.EncodeToString(sum[:])
+}
+
+// accountContext 返回携带 cookie 所属账号的 ctx，用于创建上游客户端；
+// 首次见到的 Cookie 账号为空，认证后由 bindCookieAccount 设置。
+func accountContext(ctx context.Context, cookie string) context.Context {
+	uid, _ := cookieAccounts.Get(cookieKey(cookie))
+	return proxy.WithAccount(ctx, uid)
+}
+
+// bindCookieAccount 记住 cookie 所属的账号，并使 ctx 之后的请求改用该账号的固定代理。
+func bindCookieAccount(ctx context.Context, cookie, uid string) {
+	if uid == "" {
+		return
+	}
+	cookieAccounts.Put(cookieKey(cookie), uid)
+	proxy.SetAccount(ctx, uid)
+}
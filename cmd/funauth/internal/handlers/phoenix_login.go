@@ -6,14 +6,20 @@ import (
 	"strings"
 
 	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/audit"
+	"github.com/Yeah114/FunAuth/internal/session"
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
-	api.POST("/phoenix/login", func(c *gin.Context) {
+	api.POST("/phoenix/login", requireToken(scopePhoenix, true), func(c *gin.Context) {
+		rec := startAudit(c, audit.EventLogin)
+		defer rec.finish()
+
 		rawAuthorization := c.GetHeader("Authorization")
 		bearerToken := strings.TrimPrefix(rawAuthorization, "Bearer ")
 		if bearerToken == "" {
+			rec.fail("bad_request", nil)
 			c.JSON(http.StatusOK, LoginResponse{
 				SuccessStates: false,
 				Message:       Message{Information: "Login: Authorization header missing Bearer token"},
@@ -23,6 +29,7 @@ func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
 
 		var req LoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
+			rec.fail("bad_request", err)
 			c.JSON(http.StatusOK, LoginResponse{
 				SuccessStates: false,
 				Message:       Message{Information: fmt.Sprintf("Login: 绑定请求体时出现问题, 原因是 %v", err)},
@@ -33,9 +40,23 @@ func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
 		if cookieStr == "" {
 			cookieStr = fixedCookie
 		}
+		rec.ev.ServerCode = req.ServerCode
+		rec.ev.Mode = auth.LoginMode(req.ServerCode)
+		rec.redact(cookieStr, req.FBToken, req.ServerPassword, req.Password)
+
+		if status, err := reserveTenantLogin(c); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, LoginResponse{
+				SuccessStates: false,
+				Message:       Message{Information: fmt.Sprintf("Login: %v", err)},
+			})
+			return
+		}
 
-		cli, err := auth.NewG79Client(c.Request.Context())
+		ctx := accountContext(c.Request.Context(), cookieStr)
+		cli, err := auth.NewG79Client(ctx)
 		if err != nil {
+			rec.fail("client_init", err)
 			c.JSON(http.StatusOK, LoginResponse{
 				SuccessStates: false,
 				Message:       Message{Information: fmt.Sprintf("Login: 初始化客户端时出现问题, 原因是 %v", err)},
@@ -44,20 +65,34 @@ func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
 		}
 
 		if err := cli.G79AuthenticateWithCookie(cookieStr); err != nil {
+			rec.fail("cookie_auth", err)
 			c.JSON(http.StatusOK, LoginResponse{
 				SuccessStates: false,
 				Message:       Message{Information: fmt.Sprintf("Login: 使用 Cookie 认证时出现问题, 原因是 %v", err)},
 			})
 			return
 		}
+		bindCookieAccount(ctx, cookieStr, cli.UserID)
+
+		rec.ev.AccountUID = cli.UserID
 
-		loginRes, err := auth.Login(c.Request.Context(), cli, auth.LoginParams{
+		if status, err := checkTenantAccount(c, cli.UserID); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, LoginResponse{
+				SuccessStates: false,
+				Message:       Message{Information: fmt.Sprintf("Login: %v", err)},
+			})
+			return
+		}
+
+		loginRes, err := auth.Login(ctx, cli, auth.LoginParams{
 			ServerCode:      req.ServerCode,
 			ServerPassword:  req.ServerPassword,
 			ClientPublicKey: req.ClientPublicKey,
 		})
 		if err != nil {
-			c.JSON(http.StatusOK, LoginResponse{
+			rec.fail("login", err)
+			c.JSON(loginErrorStatus(c, err), LoginResponse{
 				SuccessStates: false,
 				Message:       Message{Information: fmt.Sprintf("Login: 登录到租赁服时出现问题, 原因是 %v", err)},
 			})
@@ -67,8 +102,9 @@ func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
 		enableSkin := true
 		var skinInfo SkinInfo
 		if enableSkin {
-			authSkinInfo, err := auth.GetSkinInfo(cli)
+			authSkinInfo, err := auth.GetSkinInfo(cli, skinItemID)
 			if err != nil {
+				rec.fail("skin", err)
 				c.JSON(http.StatusOK, LoginResponse{
 					SuccessStates: false,
 					Message:       Message{Information: fmt.Sprintf("Login: 获取皮肤信息时出现问题, 原因是 %v", err)},
@@ -82,20 +118,13 @@ func RegisterPhoenixLoginRoute(api *gin.RouterGroup) {
 			}
 		}
 
-		resetSession(bearerToken)
-		session := getSessionByBearer(c)
-		if session == nil {
-			c.JSON(http.StatusOK, LoginResponse{
-				SuccessStates: false,
-				Message:       Message{Information: fmt.Sprintf("Login: 无效的 Auth Bearer (%s)", rawAuthorization)},
-			})
-			return
-		}
-		session.Store(sessionKeyEntityID, loginRes.EntityID)
-		session.Store(sessionKeyEngineVersion, loginRes.EngineVersion)
-		session.Store(sessionKeyPatchVersion, loginRes.PatchVersion)
-		session.Store(sessionKeyUserID, loginRes.UID)
-		session.Store(sessionKeyIsPC, loginRes.IsPC)
+		saveSession(bearerToken, session.Data{
+			EntityID:      loginRes.EntityID,
+			EngineVersion: loginRes.EngineVersion,
+			PatchVersion:  loginRes.PatchVersion,
+			UserID:        loginRes.UID,
+			IsPC:          loginRes.IsPC,
+		})
 
 		resp := LoginResponse{
 			SuccessStates:  true,
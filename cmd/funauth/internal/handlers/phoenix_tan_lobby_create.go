@@ -5,13 +5,18 @@ import (
 	"net/http"
 
 	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/audit"
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterPhoenixTanLobbyCreateRoute(api *gin.RouterGroup) {
 	api.POST("/phoenix/tan_lobby_create", func(c *gin.Context) {
+		rec := startAudit(c, audit.EventTanLobbyCreate)
+		defer rec.finish()
+
 		var req TanLobbyCreateRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
+			rec.fail("bad_request", err)
 			c.JSON(http.StatusOK, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: 绑定请求体时出现问题, 原因是 %v", err)})
 			return
 		}
@@ -19,21 +24,41 @@ func RegisterPhoenixTanLobbyCreateRoute(api *gin.RouterGroup) {
 		if cookieStr == "" {
 			cookieStr = fixedCookie
 		}
+		rec.redact(cookieStr)
+
+		if status, err := reserveTenantLogin(c); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: %v", err)})
+			return
+		}
 
-		cli, err := auth.NewG79Client(c.Request.Context())
+		ctx := accountContext(c.Request.Context(), cookieStr)
+		cli, err := auth.NewG79Client(ctx)
 		if err != nil {
+			rec.fail("client_init", err)
 			c.JSON(http.StatusOK, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: 初始化客户端时出现问题, 原因是 %v", err)})
 			return
 		}
 
 		if err := cli.G79AuthenticateWithCookie(cookieStr); err != nil {
+			rec.fail("cookie_auth", err)
 			c.JSON(http.StatusOK, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: 使用 Cookie 认证时出现问题, 原因是 %v", err)})
 			return
 		}
+		bindCookieAccount(ctx, cookieStr, cli.UserID)
+
+		rec.ev.AccountUID = cli.UserID
+
+		if status, err := checkTenantAccount(c, cli.UserID); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: %v", err)})
+			return
+		}
 
-		createRes, err := auth.TanLobbyCreate(c.Request.Context(), cli)
+		createRes, err := auth.TanLobbyCreate(ctx, cli)
 		if err != nil {
-			c.JSON(http.StatusOK, TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: %v", err)})
+			rec.fail("create", err)
+			c.JSON(loginErrorStatus(c, err), TanLobbyCreateResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyCreate: %v", err)})
 			return
 		}
 
@@ -5,13 +5,18 @@ import (
 	"net/http"
 
 	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/audit"
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterPhoenixTanLobbyLoginRoute(api *gin.RouterGroup) {
 	api.POST("/phoenix/tan_lobby_login", func(c *gin.Context) {
+		rec := startAudit(c, audit.EventTanLobbyLogin)
+		defer rec.finish()
+
 		var req TanLobbyLoginRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
+			rec.fail("bad_request", err)
 			c.JSON(http.StatusOK, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: 绑定请求体时出现问题, 原因是 %v", err)})
 			return
 		}
@@ -19,31 +24,53 @@ func RegisterPhoenixTanLobbyLoginRoute(api *gin.RouterGroup) {
 		if cookieStr == "" {
 			cookieStr = fixedCookie
 		}
+		rec.redact(cookieStr)
+		rec.ev.ServerCode = req.RoomID
+
+		if status, err := reserveTenantLogin(c); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: %v", err)})
+			return
+		}
 
-		cli, err := auth.NewG79Client(c.Request.Context())
+		ctx := accountContext(c.Request.Context(), cookieStr)
+		cli, err := auth.NewG79Client(ctx)
 		if err != nil {
+			rec.fail("client_init", err)
 			c.JSON(http.StatusOK, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: 初始化客户端时出现问题, 原因是 %v", err)})
 			return
 		}
 
 		if err := cli.G79AuthenticateWithCookie(cookieStr); err != nil {
+			rec.fail("cookie_auth", err)
 			c.JSON(http.StatusOK, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: 使用 Cookie 认证时出现问题, 原因是 %v", err)})
 			return
 		}
+		bindCookieAccount(ctx, cookieStr, cli.UserID)
+
+		rec.ev.AccountUID = cli.UserID
+
+		if status, err := checkTenantAccount(c, cli.UserID); err != nil {
+			rec.fail("tenant", err)
+			c.JSON(status, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: %v", err)})
+			return
+		}
 
-		loginRes, err := auth.TanLobbyLogin(c.Request.Context(), cli, auth.TanLobbyLoginParams{
+		loginRes, err := auth.TanLobbyLogin(ctx, cli, auth.TanLobbyLoginParams{
 			RoomID: req.RoomID,
 		})
 		if err != nil {
-			c.JSON(http.StatusOK, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: %v", err)})
+			rec.fail("login", err)
+			c.JSON(loginErrorStatus(c, err), TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: %v", err)})
 			return
 		}
 
 		enableSkin := true
 		var skinInfo SkinInfo
 		if enableSkin {
-			authSkinInfo, err := auth.GetSkinInfo(cli)
+			authSkinInfo, err := auth.GetSkinInfo(cli, skinItemID)
 			if err != nil {
+				rec.fail("skin", err)
 				c.JSON(http.StatusOK, TanLobbyLoginResponse{Success: false, ErrorInfo: fmt.Sprintf("TanLobbyLogin: 获取皮肤信息时出现问题, 原因是 %v", err)})
 				return
 			}
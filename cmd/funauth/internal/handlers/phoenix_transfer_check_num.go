@@ -3,61 +3,70 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	auth "github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/audit"
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterPhoenixTransferCheckNumRoute(api *gin.RouterGroup) {
-	api.POST("/phoenix/transfer_check_num", func(c *gin.Context) {
+	api.POST("/phoenix/transfer_check_num", requireToken(scopePhoenix, false), func(c *gin.Context) {
+		rec := startAudit(c, audit.EventCheckNum)
+		defer rec.finish()
+
 		var req TransferCheckNumRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
+			rec.fail("bad_request", err)
 			c.Status(http.StatusBadRequest)
 			return
 		}
 
 		// 1. 优先从请求体获取参数，获取不到则从 Session 加载
+		sess, hasSession := getSessionByBearer(c)
+
 		// 处理 EngineVersion
 		engineVersionStr := req.EngineVersion
-		if engineVersionStr == "" {
-			if session := getSessionByBearer(c); session != nil {
-				if raw, ok := session.Load(sessionKeyEngineVersion); ok {
-					engineVersionStr, _ = raw.(string)
-				}
-			}
+		if engineVersionStr == "" && hasSession {
+			engineVersionStr = sess.EngineVersion
 		}
 
 		// 处理 PatchVersion
 		patchVersionStr := req.PatchVersion
-		if patchVersionStr == "" {
-			if session := getSessionByBearer(c); session != nil {
-				if raw, ok := session.Load(sessionKeyPatchVersion); ok {
-					patchVersionStr, _ = raw.(string)
-				}
-			}
+		if patchVersionStr == "" && hasSession {
+			patchVersionStr = sess.PatchVersion
 		}
 
 		// 处理 IsPC（用指针区分"未传"和"传false"）
 		var isPC bool
 		if req.IsPC != nil {
 			isPC = *req.IsPC
-		} else {
-			if session := getSessionByBearer(c); session != nil {
-				if raw, ok := session.Load(sessionKeyIsPC); ok {
-					isPC, _ = raw.(bool)
-				}
-			}
+		} else if hasSession {
+			isPC = sess.IsPC
+		}
+
+		if hasSession {
+			rec.ev.AccountUID = sess.UserID
+		}
+		rec.ev.Mode = "mobile"
+		if isPC {
+			rec.ev.Mode = "pc"
 		}
 
 		// 2. 调用 auth 方法（参数已优先取请求体的值）
+		ctx := c.Request.Context()
+		if req.NoCache || strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			ctx = auth.WithoutCheckNumCache(ctx)
+		}
 		value, err := auth.TransferCheckNum(
-			c.Request.Context(),
+			ctx,
 			isPC,
 			req.Data,
 			engineVersionStr,
 			patchVersionStr,
 		)
 		if err != nil {
+			rec.fail("check_num", err)
 			c.JSON(http.StatusOK, TransferCheckNumResponse{
 				Success: false,
 				Message: fmt.Sprintf("TransferCheckNum: %v", err),
@@ -9,29 +9,19 @@ import (
 )
 
 func RegisterPhoenixTransferStartTypeRoute(api *gin.RouterGroup) {
-	api.GET("/phoenix/transfer_start_type", func(c *gin.Context) {
+	api.GET("/phoenix/transfer_start_type", requireToken(scopePhoenix, true), func(c *gin.Context) {
 		var q TransferStartTypeQuery
 		if err := c.ShouldBindQuery(&q); err != nil {
 			c.Status(http.StatusBadRequest)
 			return
 		}
 
-		session := getSessionByBearer(c)
-		if session == nil {
+		sess, ok := getSessionByBearer(c)
+		if !ok || sess.UserID == "" {
 			c.Status(http.StatusBadRequest)
 			return
 		}
-		userIDRaw, ok := session.Load(sessionKeyUserID)
-		if !ok {
-			c.Status(http.StatusBadRequest)
-			return
-		}
-		userID, ok := userIDRaw.(string)
-		if !ok || userID == "" {
-			c.Status(http.StatusBadRequest)
-			return
-		}
-		enc, err := auth.TransferStartType(userID, q.Content)
+		res, err := auth.TransferStartTypeDetail(sess.UserID, q.Content)
 		if err != nil {
 			c.JSON(http.StatusOK, TransferStartTypeResponse{
 				Success: false,
@@ -39,6 +29,11 @@ func RegisterPhoenixTransferStartTypeRoute(api *gin.RouterGroup) {
 			})
 			return
 		}
-		c.JSON(http.StatusOK, TransferStartTypeResponse{Success: true, Message: "ok", Data: enc})
+		resp := TransferStartTypeResponse{Success: true, Message: "ok", Data: res.Encrypted}
+		// dry_run 时附带解密后的明文，便于排查
+		if q.DryRun {
+			resp.Plaintext = res.Plaintext
+		}
+		c.JSON(http.StatusOK, resp)
 	})
 }
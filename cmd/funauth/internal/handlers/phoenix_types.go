@@ -38,6 +38,7 @@ type TransferCheckNumRequest struct {
 	EngineVersion  string `json:"engine_version,omitempty"`
 	PatchVersion   string `json:"patch_version,omitempty"`
 	IsPC           *bool  `json:"is_pc,omitempty"`
+	NoCache        bool   `json:"no_cache,omitempty"`
 }
 
 type TransferCheckNumResponse struct {
@@ -48,12 +49,14 @@ type TransferCheckNumResponse struct {
 
 type TransferStartTypeQuery struct {
 	Content string `form:"content"`
+	DryRun  bool   `form:"dry_run"`
 }
 
 type TransferStartTypeResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Data      any    `json:"data,omitempty"`
+	Plaintext string `json:"plaintext,omitempty"`
 }
 
 // TanLobbyTransferServersResponse ..
@@ -1,38 +1,119 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
 	"strings"
-	"sync"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/internal/session"
+	"github.com/Yeah114/FunAuth/internal/token"
 )
 
-const (
-	sessionKeyEntityID      = "SESSION_KEY_ENTITY_ID"
-	sessionKeyEngineVersion = "SESSION_KEY_ENGINE_VERSION"
-	sessionKeyPatchVersion  = "SESSION_KEY_PATCH_VERSION"
-	sessionKeyUserID        = "SESSION_KEY_USER_ID"
-	sessionKeyIsPC          = "SESSION_KEY_IS_PC"
+// 令牌权限范围。
+const scopePhoenix = "phoenix"
+
+// tokenScopes 为 /api/new 可以签发的全部权限范围。
+var tokenScopes = []string{scopePhoenix}
+
+const tokenClaimsKey = "funauth.token_claims"
+
+// 由 Setup 创建。
+var (
+	sessionStore session.Store
+	tokenIssuer  *token.Issuer
+	// tokenKeys 为最近一次应用的 token.keys 配置，用于判断重新加载时密钥是否变化。
+	tokenKeys string
 )
 
-var sessionStore sync.Map // map[string]*sync.Map
+// setupSessions 按 cfg 创建会话存储；持久化后端打开失败时退回内存存储。
+func setupSessions(cfg session.Config) {
+	store, err := session.Open(cfg)
+	if err != nil {
+		log.Printf("[session] open %s backend: %v, falling back to memory", cfg.Backend, err)
+		store = session.NewMemoryStore(cfg)
+	}
+	sessionStore = store
+	sessionsGauge.Set(func() float64 { return float64(store.Len()) })
+}
+
+func sessions() session.Store { return sessionStore }
+
+// setupTokens 按 opts 创建令牌签发器。
+func setupTokens(opts token.Options) error {
+	issuer, err := token.Open(opts)
+	if err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+	tokenIssuer, tokenKeys = issuer, opts.Keys
+	return nil
+}
+
+func tokens() *token.Issuer { return tokenIssuer }
 
-func getSessionByBearer(c *gin.Context) *sync.Map {
-	bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
-	if bearer == "" {
+// applyTokenKeys 以 spec 替换令牌签发器的密钥环；spec 为空时保留当前密钥环，
+// 避免重新加载时误删密钥而使已签发的令牌全部失效。
+func applyTokenKeys(spec string) error {
+	if spec == "" || spec == tokenKeys {
 		return nil
 	}
-	if value, ok := sessionStore.Load(bearer); ok {
-		return value.(*sync.Map)
+	ring, err := token.ParseKeyRing(spec)
+	if err != nil {
+		return fmt.Errorf("token keys: %w", err)
+	}
+	if tokenKeys != "" {
+		log.Printf("[token] key ring updated, signing with %q, accepting %s", ring.ActiveID(), strings.Join(ring.IDs(), ","))
 	}
-	session := &sync.Map{}
-	sessionStore.Store(bearer, session)
-	return session
+	tokens().SetKeyRing(ring)
+	tokenKeys = spec
+	return nil
 }
 
-func resetSession(bearer string) {
-	if bearer == "" {
-		return
+// requireToken 校验 Bearer 令牌的签名、有效期、吊销状态与权限范围，
+// 并把 Claims 保存到请求上下文中；required 为 false 时允许不携带 Authorization。
+func requireToken(scope string, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer := bearerFromRequest(c)
+		if bearer == "" {
+			if required {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "message": "missing bearer token"})
+				return
+			}
+			c.Next()
+			return
+		}
+		claims, err := tokens().Verify(bearer)
+		if err == nil && !claims.Allows(scope) {
+			err = token.ErrScope
+		}
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, token.ErrScope) {
+				status = http.StatusForbidden
+			}
+			c.AbortWithStatusJSON(status, gin.H{"success": false, "message": err.Error()})
+			return
+		}
+		c.Set(tokenClaimsKey, claims)
+		c.Next()
 	}
-	sessionStore.Delete(bearer)
+}
+
+func bearerFromRequest(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+// getSessionByBearer 返回请求 Bearer 对应的会话；未登录或会话已过期时返回 false。
+//
+// 只有 /phoenix/login 会创建会话，未知的 Bearer 不会被自动创建。
+func getSessionByBearer(c *gin.Context) (session.Data, bool) {
+	return sessions().Get(bearerFromRequest(c))
+}
+
+// saveSession 以登录结果覆盖 bearer 对应的会话。
+func saveSession(bearer string, data session.Data) {
+	sessions().Put(bearer, data)
 }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/internal/tenant"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	tenantKey    = "funauth.tenant"
+)
+
+var errInvalidAPIKey = errors.New("invalid api key")
+
+// tenantRegistry 为当前的租户注册表，由 ApplyConfig 设置；为 nil 时表示不启用 API Key 认证。
+var tenantRegistry atomic.Pointer[tenant.Registry]
+
+func tenants() *tenant.Registry {
+	return tenantRegistry.Load()
+}
+
+// applyTenants 以 list 替换租户配置；已有注册表时原地替换以保留用量计数。
+func applyTenants(list []tenant.Tenant) error {
+	cur := tenants()
+	switch {
+	case len(list) == 0:
+		if cur != nil {
+			log.Printf("[tenant] no tenants configured, api key authentication disabled")
+		}
+		tenantRegistry.Store(nil)
+		return nil
+	case cur != nil:
+		if err := cur.Replace(list); err != nil {
+			return err
+		}
+	default:
+		reg, err := tenant.NewRegistry(list)
+		if err != nil {
+			return err
+		}
+		tenantRegistry.Store(reg)
+	}
+	log.Printf("[tenant] loaded %d tenants", len(list))
+	return nil
+}
+
+// APIKeyAuth 要求请求头 X-API-Key 对应一个租户，且该租户可以访问当前路由；
+// 未提供 API Key 时按已校验的客户端证书（mTLS）查找租户。未配置租户时不做任何检查。
+func APIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reg := tenants()
+		if reg == nil {
+			c.Next()
+			return
+		}
+		var t *tenant.Tenant
+		var ok bool
+		if key := strings.TrimSpace(c.GetHeader(apiKeyHeader)); key != "" {
+			t, ok = reg.Lookup(key)
+		} else if cs := c.Request.TLS; cs != nil && len(cs.VerifiedChains) > 0 {
+			t, ok = reg.LookupCert(cs.VerifiedChains[0][0])
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "message": errInvalidAPIKey.Error()})
+			return
+		}
+		route := c.FullPath()
+		path := route
+		if route == "" {
+			// 未匹配路由的请求按原始路径做权限判断，但统一计入 "unmatched"，避免任意路径撑大统计
+			path = c.Request.URL.Path
+			route = "unmatched"
+		}
+		if !t.AllowsEndpoint(path) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"success": false, "message": tenant.ErrEndpointDenied.Error()})
+			return
+		}
+		reg.RecordRequest(t, route)
+		c.Set(tenantKey, t)
+		c.Next()
+	}
+}
+
+func tenantFromContext(c *gin.Context) *tenant.Tenant {
+	v, ok := c.Get(tenantKey)
+	if !ok {
+		return nil
+	}
+	return v.(*tenant.Tenant)
+}
+
+// reserveTenantLogin 在创建客户端、访问上游之前确认请求带有租户并占用一次登录配额，
+// 返回建议的 HTTP 状态码；未启用租户时总是通过。
+func reserveTenantLogin(c *gin.Context) (int, error) {
+	t, reg := tenantFromContext(c), tenants()
+	if reg == nil {
+		return http.StatusOK, nil
+	}
+	if t == nil {
+		return http.StatusUnauthorized, errInvalidAPIKey
+	}
+	if err := reg.ReserveLogin(t); err != nil {
+		return http.StatusTooManyRequests, err
+	}
+	return http.StatusOK, nil
+}
+
+// checkTenantAccount 在 Cookie 认证确定账号之后检查租户的账号白名单，返回建议的 HTTP 状态码。
+func checkTenantAccount(c *gin.Context, userID string) (int, error) {
+	if t := tenantFromContext(c); t != nil && tenants() != nil && !t.AllowsAccount(userID) {
+		return http.StatusForbidden, tenant.ErrAccountDenied
+	}
+	return http.StatusOK, nil
+}
+
+// RegisterUsageRoutes 注册 GET /usage：返回请求所用 API Key 对应租户的用量。
+func RegisterUsageRoutes(api *gin.RouterGroup) {
+	api.GET("/usage", func(c *gin.Context) {
+		t, reg := tenantFromContext(c), tenants()
+		if t == nil || reg == nil {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "tenants not configured"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "usage": reg.Usage(t)})
+	})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/internal/token"
+)
+
+// RegisterTokenRoutes 注册令牌查询与吊销接口。持有令牌即可查询或吊销它本身。
+func RegisterTokenRoutes(api *gin.RouterGroup) {
+	api.POST("/token/introspect", handleTokenIntrospect)
+	api.POST("/token/revoke", handleTokenRevoke)
+}
+
+// tokenFromRequest 优先读取请求体中的 token，其次读取 Authorization 头。
+func tokenFromRequest(c *gin.Context) string {
+	var req TokenRequest
+	if c.Request.ContentLength != 0 {
+		_ = c.ShouldBindJSON(&req)
+	}
+	if req.Token != "" {
+		return req.Token
+	}
+	return bearerFromRequest(c)
+}
+
+func handleTokenIntrospect(c *gin.Context) {
+	tok := tokenFromRequest(c)
+	if tok == "" {
+		c.JSON(http.StatusBadRequest, TokenIntrospectResponse{Reason: "missing token"})
+		return
+	}
+	claims, err := tokens().Verify(tok)
+	resp := TokenIntrospectResponse{Active: err == nil}
+	if err != nil {
+		resp.Reason = err.Error()
+	}
+	// 签名有效时（即使已过期或被吊销）返回声明内容
+	if err == nil || errors.Is(err, token.ErrExpired) || errors.Is(err, token.ErrRevoked) {
+		resp.ID = claims.ID
+		resp.KeyID = claims.KeyID
+		resp.Scopes = claims.Scopes
+		resp.IssuedAt = claims.IssuedAt
+		resp.ExpiresAt = claims.ExpiresAt
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func handleTokenRevoke(c *gin.Context) {
+	tok := tokenFromRequest(c)
+	if tok == "" {
+		c.JSON(http.StatusBadRequest, TokenRevokeResponse{Success: false, Message: "missing token"})
+		return
+	}
+	if _, err := tokens().Revoke(tok); err != nil {
+		c.JSON(http.StatusBadRequest, TokenRevokeResponse{Success: false, Message: err.Error()})
+		return
+	}
+	// 同时清除该令牌关联的会话
+	sessions().Delete(tok)
+	c.JSON(http.StatusOK, TokenRevokeResponse{Success: true, Message: "revoked"})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+// TokenRequest 为令牌吊销与查询请求；Token 为空时使用 Authorization 头中的 Bearer。
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+// TokenIntrospectResponse ..
+type TokenIntrospectResponse struct {
+	Active    bool     `json:"active"`
+	Reason    string   `json:"reason,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	KeyID     string   `json:"kid,omitempty"`
+	Scopes    []string `json:"scope,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+}
+
+// TokenRevokeResponse ..
+type TokenRevokeResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
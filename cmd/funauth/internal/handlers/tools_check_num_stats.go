@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterToolsCheckNumStatsRoute 注册校验值生成后端的状态接口（含 worker 池队列深度与缓存命中统计）。
+func RegisterToolsCheckNumStatsRoute(api *gin.RouterGroup) {
+	api.GET("/tools/check_num/stats", func(c *gin.Context) {
+		resp := CheckNumStatsResponse{Success: true, Backend: auth.CheckNumBackend()}
+		if stats, ok := auth.CheckNumPoolStats(); ok {
+			resp.Pool = &stats
+		}
+		if stats, ok := auth.CheckNumCacheStats(); ok {
+			resp.Cache = &stats
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+}
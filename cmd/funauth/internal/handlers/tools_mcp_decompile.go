@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMCPMaxBytes         = 4 << 20
+	defaultMCPDecompileTimeout = 60 * time.Second
+)
+
+// RegisterToolsMCPDecompileRoute 注册 dynamic MCP 反编译接口。
+//
+// cfg.MaxBytes 为解码后 MCP 的最大字节数（默认 4MiB），cfg.DecompileTimeout 为反编译超时（默认 60s）；
+// 同时运行的反编译进程数由 auth.Options.MCPDecompileConcurrency 限制（见 auth.DecompileMCP）。
+func RegisterToolsMCPDecompileRoute(api *gin.RouterGroup, cfg config.MCP) {
+	maxBytes := int64(cfg.MaxBytes)
+	if maxBytes <= 0 {
+		maxBytes = defaultMCPMaxBytes
+	}
+	timeout := time.Duration(cfg.DecompileTimeout)
+	if timeout <= 0 {
+		timeout = defaultMCPDecompileTimeout
+	}
+
+	api.POST("/tools/mcp/decompile", func(c *gin.Context) {
+		// 十六进制文本与 JSON 包装会使请求体大约为 MCP 的两倍
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*maxBytes+4096)
+		var req MCPDecompileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusOK, MCPDecompileResponse{Success: false, Message: fmt.Sprintf("MCPDecompile: 绑定请求体时出现问题, 原因是 %v", err)})
+			return
+		}
+		input, format := req.Hex, auth.MCPInputHex
+		if input == "" {
+			input, format = req.Data, auth.MCPInputData
+		}
+		mcp, err := auth.DecodeMCPInput([]byte(input), format)
+		if err != nil {
+			c.JSON(http.StatusOK, MCPDecompileResponse{Success: false, Message: fmt.Sprintf("MCPDecompile: %v", err)})
+			return
+		}
+		if int64(len(mcp)) > maxBytes {
+			c.JSON(http.StatusOK, MCPDecompileResponse{Success: false, Message: fmt.Sprintf("MCPDecompile: mcp too large (%d > %d bytes)", len(mcp), maxBytes)})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		code, err := auth.DecompileMCP(ctx, mcp)
+		if err != nil {
+			status := http.StatusOK
+			if errors.Is(err, auth.ErrMCPDecompileBusy) {
+				status = http.StatusTooManyRequests
+			}
+			c.JSON(status, MCPDecompileResponse{Success: false, Size: len(mcp), Message: fmt.Sprintf("MCPDecompile: %v", err)})
+			return
+		}
+		c.JSON(http.StatusOK, MCPDecompileResponse{Success: true, Message: "ok", Size: len(mcp), Code: code})
+	})
+}
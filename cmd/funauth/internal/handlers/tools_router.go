@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+// 汇总注册调试/自测类工具接口
+func RegisterToolsRoutes(api *gin.RouterGroup, cfg config.MCP) {
+	RegisterToolsCheckNumStatsRoute(api)
+	RegisterToolsMCPDecompileRoute(api, cfg)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/Yeah114/FunAuth/internal/lru"
+	"github.com/Yeah114/FunAuth/internal/workerpool"
+)
+
+// CheckNumStatsResponse ..
+type CheckNumStatsResponse struct {
+	Success bool              `json:"success"`
+	Backend string            `json:"backend"`
+	Pool    *workerpool.Stats `json:"pool,omitempty"`
+	Cache   *lru.Stats        `json:"cache,omitempty"`
+}
+
+// MCPDecompileRequest ..
+type MCPDecompileRequest struct {
+	Hex  string `json:"hex,omitempty"`
+	Data string `json:"data,omitempty"`
+}
+
+// MCPDecompileResponse ..
+type MCPDecompileResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Size    int    `json:"size,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
@@ -8,21 +8,32 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Yeah114/FunAuth/cmd/funauth/internal/handlers"
+	"github.com/Yeah114/FunAuth/internal/config"
 )
 
-func NewRouter() *gin.Engine {
+// NewRouter 注册全部路由；调用前应已通过 handlers.Setup 应用 cfg。
+func NewRouter(cfg *config.Config) *gin.Engine {
 	// 确保 gin 的默认日志写到 stdout
 	gin.DefaultWriter = io.MultiWriter(os.Stdout)
 	gin.DefaultErrorWriter = io.MultiWriter(os.Stdout)
 	log.SetOutput(os.Stdout)
 
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	r.Use(gin.Logger(), gin.Recovery(), handlers.Metrics(), handlers.Drain(), handlers.RateLimit())
 	_ = r.SetTrustedProxies([]string{"127.0.0.1"})
 
-	api := r.Group("/api")
+	handlers.RegisterMetricsRoute(r, cfg.Metrics)
+	handlers.RegisterHealthRoutes(r)
+
+	// 管理接口使用独立的管理员令牌，不经过 API Key 认证
+	handlers.RegisterAdminRoutes(r.Group("/api"), cfg.Admin)
+
+	api := r.Group("/api", handlers.APIKeyAuth())
 	handlers.RegisterNewRoutes(api)
+	handlers.RegisterTokenRoutes(api)
+	handlers.RegisterUsageRoutes(api)
 	handlers.RegisterPhoenixRoutes(api)
+	handlers.RegisterToolsRoutes(api, cfg.MCP)
 
 	return r
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/certs"
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+// listen 返回 HTTP 服务使用的监听套接字：优先使用 systemd socket activation 传入的套接字，
+// 否则监听 server.addr 中的每个地址（TCP 或 unix:/path）。server.free_port 开启时先结束占用端口的其他 FunAuth 进程。
+//
+// 配置了 TLS 证书时，server.tls.addr 为空则 server.addr 中的 TCP 地址使用 HTTPS，否则另外在 server.tls.addr 上监听 HTTPS；
+// unix 套接字只用于本机访问，总是明文。
+func listen(cfg *config.Config) ([]net.Listener, error) {
+	var tlsConfig *tls.Config
+	if tc := cfg.Server.TLS; tc.Enabled() {
+		r, err := certs.New(tc.CertOptions())
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		tlsConfig = r.TLSConfig()
+	}
+	mainTLS := tlsConfig != nil && cfg.Server.TLS.Addr == ""
+
+	var lns []net.Listener
+	fail := func(err error) ([]net.Listener, error) {
+		for _, ln := range lns {
+			ln.Close()
+		}
+		return nil, err
+	}
+	add := func(ln net.Listener, useTLS bool) {
+		if useTLS {
+			log.Printf("[server] serving https on %s", ln.Addr())
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+		lns = append(lns, ln)
+	}
+
+	ln, err := systemdListener()
+	if err != nil {
+		return nil, err
+	}
+	if ln != nil {
+		log.Printf("[server] using socket from systemd: %s", ln.Addr())
+		add(ln, mainTLS)
+	} else {
+		mode, err := cfg.Server.SocketMode()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range cfg.Server.Addrs() {
+			if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+				ln, err := listenUnix(path, mode)
+				if err != nil {
+					return fail(err)
+				}
+				add(ln, false)
+				continue
+			}
+			ln, err := listenTCP(cfg, addr)
+			if err != nil {
+				return fail(err)
+			}
+			add(ln, mainTLS)
+		}
+	}
+
+	if tlsConfig != nil && !mainTLS {
+		ln, err := listenTCP(cfg, cfg.Server.TLS.Addr)
+		if err != nil {
+			return fail(err)
+		}
+		add(ln, true)
+	}
+	return lns, nil
+}
+
+func listenTCP(cfg *config.Config, addr string) (net.Listener, error) {
+	if cfg.Server.FreePort {
+		if p, ok := parsePort(addr); ok {
+			log.Printf("[port] try free port %d before binding", p)
+			freePort(p)
+		}
+	}
+
+	lc := net.ListenConfig{}
+	if cfg.Server.ReusePort {
+		lc.Control = reusePortControl
+	}
+	log.Printf("[server] binding address: %s", addr)
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// listenUnix 监听 unix 套接字 path 并设置文件权限；上次异常退出遗留的套接字文件会被清理。
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	log.Printf("[server] binding unix socket: %s", path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// removeStaleSocket 删除无进程监听的套接字文件；仍有进程监听或 path 不是套接字时返回错误。
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode().IsRegular() || fi.IsDir() {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("unix socket %s is in use by another process", path)
+	}
+	log.Printf("[server] removing stale unix socket %s", path)
+	return os.Remove(path)
+}
+
+// sameExecutable 报告可执行文件 image 是否就是当前进程的可执行文件 self：
+// 先按文件本身比较（Unix 为设备号与 inode，Windows 为卷序列号与文件索引），无法访问时再比较解析后的绝对路径。
+func sameExecutable(image, self string) bool {
+	if a, err := os.Stat(image); err == nil {
+		if b, err := os.Stat(self); err == nil {
+			return os.SameFile(a, b)
+		}
+	}
+	return samePath(image, self)
+}
+
+// samePath 比较解析符号链接后的绝对路径，Windows 下不区分大小写。
+func samePath(a, b string) bool {
+	a, b = resolvePath(a), resolvePath(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func resolvePath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		p = resolved
+	}
+	return filepath.Clean(p)
+}
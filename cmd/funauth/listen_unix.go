@@ -0,0 +1,51 @@
+//go:build !windows
+
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenFdsStart 为 systemd 传入的第一个套接字的文件描述符。
+const listenFdsStart = 3
+
+// systemdListener 返回 systemd socket activation（LISTEN_PID/LISTEN_FDS）传入的监听套接字，
+// 未通过 socket activation 启动时返回 nil。
+func systemdListener() (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	// 子进程不应继承这些变量
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if n > 1 {
+		log.Printf("[server] systemd passed %d sockets, only the first one is used", n)
+	}
+
+	syscall.CloseOnExec(listenFdsStart)
+	f := os.NewFile(listenFdsStart, "systemd-socket")
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var opErr error
+	err := c.Control(func(fd uintptr) {
+		opErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return opErr
+}
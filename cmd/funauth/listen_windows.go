@@ -0,0 +1,18 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// systemdListener 在 Windows 下不可用。
+func systemdListener() (net.Listener, error) {
+	return nil, nil
+}
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on windows")
+}
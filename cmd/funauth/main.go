@@ -20,27 +20,30 @@ func main() {
 	// 确保标准日志输出到 stdout（部分面板默认不抓取 stderr）
 	log.SetOutput(os.Stdout)
 
-	r := router.NewRouter()
-
-	addr := os.Getenv("FUNAUTH_ADDR")
-	if addr == "" {
-		addr = ":8080"
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
 	}
 
-	// Linux 下启动前尝试释放端口
-	if runtime.GOOS == "linux" {
-		if p, ok := parsePort(addr); ok {
-			log.Printf("[port] try free port %d before binding", p)
-			freePortLinux(p)
-		}
-	}
+	cfg := loadConfig()
+	r := router.NewRouter(cfg)
+	watchConfig()
 
-	log.Printf("[server] binding address: %s", addr)
-	if err := r.Run(addr); err != nil {
+	lns, err := listen(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := serve(lns, r, cfg); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// freePort 结束占用端口的其他 FunAuth 进程，目前仅支持 Linux。
+func freePort(port int) {
+	if runtime.GOOS == "linux" {
+		freePortLinux(port)
+	}
+}
+
 func parsePort(addr string) (int, bool) {
 	a := strings.TrimSpace(addr)
 	if a == "" {
@@ -75,27 +78,61 @@ func freePortLinux(port int) {
 	}
 	uniq := make(map[int]struct{})
 	for _, p := range pids {
+		if p <= 1 || p == os.Getpid() {
+			continue
+		}
+		if !isFunAuthPid(p) {
+			log.Printf("[port] pid=%d owns port %d but is not a FunAuth process, leaving it alone", p, port)
+			continue
+		}
 		uniq[p] = struct{}{}
 	}
+	if len(uniq) == 0 {
+		return
+	}
 	for pid := range uniq {
-		if pid <= 1 || pid == os.Getpid() {
-			continue
-		}
 		log.Printf("[port] sending SIGTERM to pid=%d for port %d", pid, port)
 		_ = syscall.Kill(pid, syscall.SIGTERM)
 	}
 	time.Sleep(1200 * time.Millisecond)
+	// 旧进程收到 SIGTERM 后先关闭监听再排空请求，已释放端口的进程不再强制结束
+	remaining := findPidsWithSS(ctx, port)
+	if len(remaining) == 0 {
+		remaining = findPidsWithLsof(ctx, port)
+	}
+	owners := make(map[int]bool)
+	for _, p := range remaining {
+		owners[p] = true
+	}
 	for pid := range uniq {
-		if pid <= 1 || pid == os.Getpid() {
-			continue
-		}
-		if alive(pid) {
+		if owners[pid] && alive(pid) {
 			log.Printf("[port] sending SIGKILL to pid=%d for port %d", pid, port)
 			_ = syscall.Kill(pid, syscall.SIGKILL)
 		}
 	}
 }
 
+// isFunAuthPid 判断进程是否运行着与当前进程相同的可执行文件：/proc/<pid>/exe 与 os.Executable() 为同一文件。
+func isFunAuthPid(pid int) bool {
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	link := "/proc/" + strconv.Itoa(pid) + "/exe"
+	if sameExecutable(link, self) {
+		return true
+	}
+	// 二进制文件被替换（升级）后，旧进程的 exe 指向已删除的旧文件并带有 " (deleted)" 后缀，此时按原路径比较
+	exe, err := os.Readlink(link)
+	if err != nil {
+		return false
+	}
+	if old, ok := strings.CutSuffix(exe, " (deleted)"); ok {
+		return samePath(old, self)
+	}
+	return false
+}
+
 func alive(pid int) bool {
 	p, err := os.FindProcess(pid)
 	if err != nil {
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/sys/windows"
+
 	"github.com/Yeah114/FunAuth/cmd/funauth/internal/router"
 )
 
@@ -18,21 +20,19 @@ func main() {
 	// 确保标准日志输出到 stdout（部分面板默认不抓取 stderr）
 	log.SetOutput(os.Stdout)
 
-	r := router.NewRouter()
-
-	addr := os.Getenv("FUNAUTH_ADDR")
-	if addr == "" {
-		addr = ":8080"
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
 	}
 
-	// Windows 下启动前尝试释放端口
-	if p, ok := parsePort(addr); ok {
-		log.Printf("[port] try free port %d before binding", p)
-		freePortWindows(p)
-	}
+	cfg := loadConfig()
+	r := router.NewRouter(cfg)
+	watchConfig()
 
-	log.Printf("[server] binding address: %s", addr)
-	if err := r.Run(addr); err != nil {
+	lns, err := listen(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := serve(lns, r, cfg); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -57,6 +57,11 @@ func parsePort(addr string) (int, bool) {
 	return v, true
 }
 
+// freePort 结束占用端口的其他 FunAuth 进程。
+func freePort(port int) {
+	freePortWindows(port)
+}
+
 func freePortWindows(port int) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -68,18 +73,44 @@ func freePortWindows(port int) {
 	}
 	uniq := make(map[int]struct{})
 	for _, p := range pids {
+		if p <= 1 || p == os.Getpid() {
+			continue
+		}
+		if !isFunAuthPid(p) {
+			log.Printf("[port] pid=%d owns port %d but is not a FunAuth process, leaving it alone", p, port)
+			continue
+		}
 		uniq[p] = struct{}{}
 	}
+	if len(uniq) == 0 {
+		return
+	}
 	for pid := range uniq {
-		if pid <= 1 || pid == os.Getpid() {
-			continue
-		}
 		log.Printf("[port] taskkill pid=%d for port %d", pid, port)
 		// 直接强制结束进程及其子进程
 		_ = exec.CommandContext(ctx, "taskkill", "/PID", strconv.Itoa(pid), "/T", "/F").Run()
 	}
 }
 
+// isFunAuthPid 判断进程是否运行着与当前进程相同的可执行文件：按完整映像路径与 os.Executable() 比较。
+func isFunAuthPid(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return false
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	return sameExecutable(windows.UTF16ToString(buf[:size]), self)
+}
+
 func findPidsWithNetstat(ctx context.Context, port int) []int {
 	cmd := exec.CommandContext(ctx, "netstat", "-ano")
 	out, err := cmd.CombinedOutput()
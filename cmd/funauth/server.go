@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/cmd/funauth/internal/handlers"
+	"github.com/Yeah114/FunAuth/internal/config"
+)
+
+// abortGrace 为排空超时、取消进行中的请求后，等待其返回的时间。
+const abortGrace = 5 * time.Second
+
+// serve 在全部 lns 上提供 HTTP 服务，直到收到 SIGINT/SIGTERM。
+//
+// 收到信号后新请求与 /readyz 返回 503，继续接受连接 server.drain_delay，使负载均衡有时间摘除本实例；
+// 随后停止接受新连接，进行中的请求（例如正在进出 DomainGame 的登录）最多等待 server.shutdown_timeout，
+// 超时后通过请求的 context 取消它们并记录被中止的请求。再次收到信号时立即退出。
+// 服务停止后依次关闭会话存储、审计日志、check-num worker 池，并写回校验值缓存。
+func serve(lns []net.Listener, handler http.Handler, cfg *config.Config) error {
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv := &http.Server{
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	defer closeResources()
+
+	errc := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func() { errc <- srv.Serve(ln) }()
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-errc:
+		return err
+	case <-sigCtx.Done():
+	}
+	stop()
+
+	handlers.BeginDrain()
+	if delay := time.Duration(cfg.Server.DrainDelay); delay > 0 {
+		log.Printf("[server] shutting down, reporting not ready for %s before closing listeners", delay)
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(delay):
+		}
+	}
+	drain := time.Duration(cfg.Server.ShutdownTimeout)
+	log.Printf("[server] closing listeners, draining in-flight requests for up to %s", drain)
+	ctx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		log.Printf("[server] all requests finished")
+		return nil
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	for _, r := range handlers.InFlight() {
+		log.Printf("[server] aborting in-flight request: %s", r)
+	}
+	cancelRequests()
+	ctx, cancel = context.WithTimeout(context.Background(), abortGrace)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		for _, r := range handlers.InFlight() {
+			log.Printf("[server] request did not stop after cancellation: %s", r)
+		}
+		return srv.Close()
+	}
+	return nil
+}
+
+// closeResources 释放 HTTP 服务之外的后台资源，出错时只记录日志。
+func closeResources() {
+	if err := handlers.Close(); err != nil {
+		log.Printf("[server] close handlers: %v", err)
+	}
+	if err := auth.CloseCheckNum(); err != nil {
+		log.Printf("[server] close check num: %v", err)
+	}
+}
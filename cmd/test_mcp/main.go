@@ -1,20 +0,0 @@
-package main
-
-import (
-	"fmt"
-	"encoding/hex"
-	"github.com/Yeah114/unmcpk"
-)
-
-func main() {
-	h := "e51f15751fced47a362a5e264d62cbf3aef1b093aa81bc946c43ceefc26cebe576129d91f0f16420414fcb2bac41194665977b607ee67e04996440aeb0a1f0c76b3ac5b1cea2908af4d91b649422f1c22aa240e160c4b7b10e67ada86fef9f715bf10ce666057f28a91e96af9a720f31fba0342172a48e60d8a37ee0708493269926b02a46d2f86cbb8787d1fab8244a445c0f8742dbe3160b2dae5a20ae63c1e58bce50e0939c3a83123162001efde6a3efa172ab4a4d0e9b7ac1569cc2cfcb4ff58691272247d56e617a229021a27b8d4420cdcd16b42139b0e6effb37e2a93d8aa47d09d3ef665029d7ea2e5cc02f9743e13bf7f7ecd07fd68911dac619a8f1c5674910d22880fd94f0382230644946da912cc88b03b5d18c11bdc922084d9d5af087f9bebfc84992bbc7d5ce6f1fad772fc249454f9e61d3db9e5aa896c8ac95b8cf3da2ca608e04df84e26a9d8e86186fd7485e34dc806308adccb1a4b74fbcf649a7b3de418c8430d18bf6cbe0ab5cf953a3f737e991eaaefab78433aa44a26730fbd9c3357ae761a2532ad4bac25e0cefe735908d38ab85590e236bab3242367fe411fd4212e51f742ce01c3086df542616469767e40246d7fe85ae03ee2541e225430e9c077dd1a3bbd7d504db7e48dbd1810ffbe0e29ac22fe861850ed870a66db49e87cf061afe5fb9fc1b84fcd2b30e82dc7451816551f7df3645d7544e7f2859d5cfbff4a800bbf4bfa9baa2a1ce770fb4335a2c513aacba8eb3f50c6ed2547e314a4de1a5de7242344dfda273f22caf12eee9a558b069e171b0ebe4d1cfbf5bef849b2728a0c831000c29022995cafa8ff071ce080ede31095303f43fd3acba4d54cf864e9a3a20fe4b926bab0a274839970ccce049c86ed2c392766f162450cee35e0942ebc92fdbe3372cae01bf093186ce3bf37f0f1c5db8cece7fc6dac7e91862ae61f9feb2f51412087165fb996105daa3c19880d0fcdc4b1873085397417e1fa87f7251f4aac3e4a73ea479e91b0e6d1d66460a8c78388b72b1e04eeff7f025652349a7dd5027da6b3b61c1e3b46316b76a8be8d882b9f6d4f183288d3733f05e84381965c53daef1030eda6dc8228a3cb7bba353cb0b39f741946d05ba61a270cc8698cd05f826d118f86169d4d2a50295906554b3684338d3679c014bb4e1a03060635de20b1649fbfa441bfe49c8a1f8af2c6152626ccd8f3363d4b045f7c85c0c92ac6b7c9e7d21e3e37dff074873b0515a077602ff0852990a3ef74035a5a738285e7853eae9fcbde8e591bc10a3dc63e4de11990a62174bb44119ac9db86e959e67d57b823193b36154b32dbb5cc621bf4e49d690d3f1cbefdf0129ab69df85d205ff07d6803a7c349bc2071e9b2f237a0ac65a00e649743c80d90938f2598f13db27ff0d0e2603a161cb380e4cbb38f868e7e192cb525ee11d61499b2486d43c5aafab68876b6a76126ae960d46a167e52058ce26cf7ebed6811f6b83000119fd294f5b3204849ba120926ab9828ffa75ca51a459404effc30e374ac0a8213106147cfbaf6e573a06c12143a1c4f59fd9254cc4a871f9a2ef3faabe6baf3b24a640208644952e593ebc7ddfb142359bdee901cea313a997c870634e90f465e4056cdddea609379728dce5fb3a8f3cb512bffe09a476575e913275a4b5df3d9a67f9e3193eb6b04fc7dba11ce9e6d77c012d1580717ed17f84ddc33f1cc627a5511fdf927f58b3831b22684deefa670a195e189dd363fc4ba18b6639303b08f883e0fdf6a7587419206c9d54d0ab98d39c6185a85059ed997b581474d3cceecc2eaab7ffc2e1353e5672710b44b860affcc07507d95b944322b90902a7b0308e602aad7895aa5c9aa044b4dc6bd588f8f84789325f36b973156390925a50a927ffff5cc4210ce3f157165bde131b9146f5003723be9746b514db32c9a2bf8b9105a24d502c0b8196ab6cd9c2f6af0640508bf3680dd219a8f2dcb609e777880c7ca815216def4f0f42af047adfe0638be5e2d2bdfbc9ed4c68a4d93def93abe8e44535a361f8463c6d0032bbaa199a4cd6fcd7e6d3577f1a6928d4e2467b5998ddc0072f3e3c93af6fc4100464d0b4c8b7ba863cfce2564f6835b408462554e1fa348512c6c93482824655c991d881d802141aff7bfa5b940c116c46ef4f4cebe4e177378acfe188b6ab9d4ed4554aae8a716962b37f1bd97d14e9c5e06b076eae16d256abbf541e008c5f001ebddb0ba97a2054e300e408d1e5ed91bbb6933ef04acb2c370fbbdefe839db287e5b89db20063fef24254035a0a19e9c7f829242970ca3db36763cce96a9297ef7834960c20b42f0dfaa9b14d99641502c055d0c7b8a42de582d81fc5bde811574f1fbdd88b3ba956f349a7c29cbe59dd34a5df53f918ced3c81c8ea2c50aa44f100b6c57bb50348aa0de5a403be8dc02f3b13c5a8277467c7bd6d391b2c91c10332e9c38b460a42d4f13bbdfde20c7f92fa1a55773a7d5b106f9290c169c3f859a3be64bf301b5d4ecee512d25f7d5b8646903ede650f569cbdd81066277caefa9814a0d4bcb85ed209946e623d99de43d76da9ad3f293edb5303d290a5229b04025397a2d373a9dfa19dddb35d6260bff47445544693b0630f089955abad5cec69e4b960071d22b9f423931b196c98e6a12450324692efe729e1dbc28ef85faf0bb5b04eca12c9910f7ce2e1076530d648562b5d507b167a9485cf0ce2d6548262b67b767bd3bb82ea3b7a345d446e885bf18cbf6d9037317516d9f812e219f3b15f27161c11547cbbfb5a2dbef18515710940fc5ea1ed24e771de146b77ae56a112869e3cb672df6b9ddf77019a2776b4f8fefda59c1069743adf72dcb58c3d02a26aa3fef1755be9640203b85268e04e0d10b88b6f7c9f9a52c137fad40e09e8e0e92ccbd8996cfba6c5644a0525029de54cc35d59553737a318e8372642b424529edc01712956e7b309d9e2290caffeb32651f86b233f6237605786cff3f39c0b622f1cc92621c41f6792443bb801928423d5c14909c91405d55b2ec64fb0890fa0f838420dadfbf5359b3a97ed6dc6039721db004743c9a46bd554db7f5b8f9dbc42551a10e86b23d73491c02d57b80734960d5034af884d142cee4fb2bbadd9ac40254a5ce851d8fb4e9c411d0cf7557084e7527b29ff1a88f1a0cf005d598f3789d371021a4be4eda219359b9eb8b1fc569e5308795a4d2a78c6c2b23a318bde4f9546d50a3bb9d19d4c6e4c651f3e97d20bd17bc31fc0ff24d58b2c14cdf3019cfc6ba945871fec918c38357d7550058fa1ed139a7e63f23e6b596ff334e5ccd1c1e61d55e7727e132b222a042006bd0a27cf8dd89e419f0aecb386f852af60bea02e32563b1222819cab14a712afa277b7cacddccabf7d804acdd9a7e47df59ca4b656f63a53ea6592ba5b67d929e0f1813c98a45abe1ea3bf56720ffec6da4977f5cb2ca28c1b01bc6c0213bc0f1feb5e77d7f24a8d2ab6b15d66dd66ae61d8c86b90c80c800f783a4e7c33632c74020bf093441ec216a6e2cb354a1d573bd6761dbd758797bab2192cdcd0c7f66d8a682a98bb4f9d08b4af5bec6b275f2fb0726850bbb1a4e60c175bdfb132286cdbf7c5c733b8fd68d4f5e8d88c5afff006bcb2d449e2248165e6d9aa85b8851f52957092f333e5cf57bcd353de9b2782bc59e71f740f547f330bd6559ae18603aaa5bc58c04876c9720543fa4f6cb8a186ec02c0a82c9166b2786c3f30d76454c677c18b62a6fd04495a7f17a3b3132a8b0ff42c33cefc485e5def14ab519600dd866cc9c5d9cc4b4e0bb2d788dc0a50a71cfb10ddb4a51848f42d5cb7eff7042583e2bb4b7bd44ad3f65b630c7e4a6fc3b77659ed262e28926d1352e9ccd291745a4c7b0d86366d5281edef00240c642fb0b17ef027434db699d4c0c6f5dffcb26f47d756b33a3c9317be61344f1f93733508c8f89b8ca852d776be120fc89650b7dcd4b2e32e39419979d9890b866bc22ce27e3df146b36f48dfda9f52ddaa8131b927735a299c084105a1982625a18fe7f757128486b2c0ffcd2edecb17d341e32bda7c7467f7da3e8bfe2f20e9d79802d27f61a228bd948949130517d43f2630fbd4fcdcb1ead228f02c9a5a8b3de2d56b28a0578f8a62164cc8cd8056380f3e73684b85c58426621ce0c9fc4229205337a7948202256aab5643195573e68904d40797aa28293be46b7402a0967cb610e99823a1a016af3a53cab272bb0d3782e25a7cd960423451244f5de63d41f1c4a2a969d0ab1c100bae8793d85a4e470ec3aa52f1663814f7d437f3f3f1ac03ed7a9b8f85ef26b7b5b5437e4ec276b9590acc842d2570cf2c2d3815bb97ca26a9d1605b1c2ff32d99923ed39f8a03635bdce00c36c6937593c0acb525404e39d15ca44be1d0d897282f38920dfafe7ead5412427d9979846aa4162dd0269e0f3c425aada3094acd5f5c38fb216b01597de0f49da63e59848f88125c085f27cf13a610d5d3d792fbd35a851567efab8c22aa95b2ee05384332fa31f45e96a4dca4c5bfb2b66870bf9280038d5c62f529b1ed449d18a822c3edf455f48bd3c071c15ac95f43a72c32cacf93556010359ecfb1684849b019b7f233f78077f9146f8ff73562533301e34aa6aa0f2a4b6bfcffed6c2348abac643ad09608dc4f2ce1108f94434b70cb44e6442051c6d8227132519acc90bb4bba140234acda5cd06adec21511ef313d6c421be83137f2212eac96ba62f6f80651d2ee6906f2181a5eb82194fc0a02aae200deb376fe9803116e626b3cdfcb56ec24d1b72d67a009cd7948c8015d1fbe8e844338929a55ffddcd9dac1f365ee0152782704190f7897602a87999e52525035079fb9df11e6bca6f447dfde9feda7eff5da8a425c539abd9d5c1d72b29f5b664a7897704e0cd38e24524e086c4b3e86dffe128e47c538f00ec84b68eb016a45096ba7c853100fcbfc411b5a490db82767626704478c895782fce49fdd5378443301a4293026256ed18ce2dafc2e381ca67596d40bfdca84cce29cf107cb3775665ced48ff176bdaf1b2bbea292896d880dd34212c8423fd4dfc9a8802d8d05da040c6e8e6db6b370c43986bd1811ff1b4306efc72f05843dd07d4df6eb02b246fd0b3a9a3a3be65cb72664c1bfa6de27b79a4a01d6c75343a1138a095d020d03ed8058834b8cd1c120d350b7b82bdf41c979a754a398cc6df2dd699bd5b8880bd5368f5862496921f2474dd6b4b8b0858426c6661e60fc196e68a936f99b6bdc9ef7991fc5af0199c49f9c9bb565c0fac0d0dc9da2e41a7144dfd1e33943a6951bd038d917499025370bba9c7995cc843433924e1f084a423ef9c230da67810a28619d498e54f53ea0265a6a0e8f8b6e05cd6e91f4ee2f6e953f36dffc37f36bb4de67fa7c42fe4b813b007474ec1d3229ab2e7d49674aa3ba697668d654d2050cd685ea61e54d741023c68207a75cfc4fafe986974efb86e1eb80552725ddd4932d33713dda70213c759aace226e7229e3ad3985df0d723190f6b811a86f2d54c9a39961b460666359e1bbbed586e62a7429683069badc44c42ad02b6760a270f3efef4724a8abead62d2798830440b5b64808e44e097ed08dc210e36f8e3d43a5456bb87361a6cb805a69fac2d7595bb7ac7049b7a32ed86765adca0419964cdd15f06dfc05deeda6725a46cc0ec13d41856765e8308867dabf2eebf2d1908cd43b9dcca3064b6b259eb95f5488eeb5ef2a6d78c601fee4fe71c7412cc7c95f3215f723bde025e6a209d50707bf1b554b1e663e27cdc2ec89d74f9e535cda8b23137d5934083df539c8c229a2c58dcf5f4167338687c02346d292420e306ef834b3c1914bf4688a16858ae4522a373a60e2136db72638ab6d92fc0ba36fdba9ab8e040ab1a7c723d876a8ce7e1abc8f30f97712c8fb8b23cf2a909f5b0dd9ccc501fc421e89ab987ab9e11d7aab5478098ef7774ebd202f0a9d3bcc0dbeac6d33b9fc58558eec0fcb8ee6c49b1b1cea5644eab671b9837972ca64261c928056d650e559b6a90ec2456c9f26a82542688d6982e9fad8c1fee8bdfac2607306f418caae5d6aa22ed37e715f12c83a773dfc6270f4db5f14e94ee4c9174eea34403ea853e677b7865cd2178fb04ce1696844688ab064694f1ce93981cb86e6a04895ea652c532de18918f7492643e059464ecbcc9e75bfc8a63f11d1a1d3f5636ade4f9dc9e9b8857d5b2688a9f4a5a49b2e4f80477d5dd6f78d1fc4a85066d966d5fc8ad6392963ea3f8aa5e3adb4dbe442c1671794061175ff2a41daf51444ee8dc9d9f2776ce9d62fe724ffea279f037b3ccfa5f72c1e5a0b6bc2cefcc2595aca511da2199fbd3485cba5ac9f93d418e5b43dcb3bb328f11bf7975e633902553b369999cb50be375ad20d62dc4fc222058f071c448a5cacc01fc7e57f1c6bbe0be050657f2dd515b09435e5d7a11efd6a7290ad40decdb7efc774e887eedd72c2d8f3f464057d9ec016aca163f0afba01032659908bccb2196576f6258d99267fdaa5e5d386cc8e277b673f643d6070dbe24f8653aa2a3c0ad33b86b6325790e1e8fd1ef0f441438217bf8f889a08b462044e2d187853785e572fc6c4de69b79d4cb053a2a5f4f09dd807f15b2d861f02b6b44852163c4347dd93d0769c0f5fc80b4cecd56022d709b4a2b94d37c57a80c6246a10ea0a8000e8840b7"
-	bs, err := hex.DecodeString(h)
-	if err != nil {
-		fmt.Println(err)
-	}
-	s, err := unmcpk.DecompileDynamicMCP(bs, "python3")
-	if err != nil {
-		fmt.Println(err)
-	}
-	fmt.Println(string(s))
-}
\ No newline at end of file
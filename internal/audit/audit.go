@@ -0,0 +1,267 @@
+// Package audit 以 JSONL 格式记录认证相关事件（登录、本地联机、校验值生成），
+// 文件超过大小上限时按 path.1、path.2 ... 轮转，并支持按条件查询。
+//
+// 事件中不保存 Cookie、入服口令与 ChainInfo；Bearer 只保存其哈希。
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 事件类型。
+const (
+	EventLogin          = "login"
+	EventTanLobbyLogin  = "tan_lobby_login"
+	EventTanLobbyCreate = "tan_lobby_create"
+	EventCheckNum       = "check_num"
+)
+
+// 事件结果。
+const (
+	OutcomeOK          = "ok"
+	OutcomeError       = "error"
+	OutcomeDenied      = "denied"
+	OutcomeRateLimited = "rate_limited"
+)
+
+const redacted = "[REDACTED]"
+
+// Event 为一条审计记录。
+type Event struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Tenant     string    `json:"tenant,omitempty"`
+	BearerHash string    `json:"bearer_hash,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	AccountUID string    `json:"account_uid,omitempty"`
+	ServerCode string    `json:"server_code,omitempty"`
+	Mode       string    `json:"mode,omitempty"`
+	Outcome    string    `json:"outcome"`
+	ErrorKind  string    `json:"error_kind,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// HashBearer 返回 Bearer 的短哈希，用于在不泄露令牌的情况下关联同一客户端的事件。
+func HashBearer(bearer string) string {
+	if bearer == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(bearer))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Redact 将 s 中出现的每个非空 secret 替换为 [REDACTED]。
+//
+// 较长的 secret 先替换，避免其中包含的较短 secret 先被替换后长值无法再匹配。
+func Redact(s string, secrets ...string) string {
+	sorted := append([]string(nil), secrets...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+// Config 为审计日志配置。
+type Config struct {
+	// Path 为日志文件路径。
+	Path string
+	// MaxBytes 为单个文件的大小上限，<=0 时为 64 MiB。
+	MaxBytes int64
+	// MaxBackups 为保留的轮转文件数，<=0 时为 5。
+	MaxBackups int
+}
+
+// Logger 写入并查询审计日志。
+type Logger struct {
+	cfg Config
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Open 打开（不存在时创建）审计日志。
+func Open(cfg Config) (*Logger, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("audit: empty path")
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 64 << 20
+	}
+	if cfg.MaxBackups <= 0 {
+		cfg.MaxBackups = 5
+	}
+	l := &Logger{cfg: cfg}
+	if err := l.openLocked(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Log 追加一条事件，写入失败只记录到标准日志。
+func (l *Logger) Log(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	ev.Time = ev.Time.UTC()
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[audit] encode event: %v", err)
+		return
+	}
+	raw = append(raw, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return
+	}
+	if l.size+int64(len(raw)) > l.cfg.MaxBytes && l.size > 0 {
+		if err := l.rotateLocked(); err != nil {
+			log.Printf("[audit] rotate %s: %v", l.cfg.Path, err)
+		}
+	}
+	n, err := l.f.Write(raw)
+	l.size += int64(n)
+	if err != nil {
+		log.Printf("[audit] write %s: %v", l.cfg.Path, err)
+	}
+}
+
+// Close 关闭日志文件。
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+func (l *Logger) openLocked() error {
+	if err := os.MkdirAll(filepath.Dir(l.cfg.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, st.Size()
+	return nil
+}
+
+func (l *Logger) rotateLocked() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+	for i := l.cfg.MaxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(backupName(l.cfg.Path, i), backupName(l.cfg.Path, i+1))
+	}
+	if err := os.Rename(l.cfg.Path, backupName(l.cfg.Path, 1)); err != nil {
+		return err
+	}
+	return l.openLocked()
+}
+
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// Filter 为查询条件，零值字段不参与过滤。
+type Filter struct {
+	From    time.Time
+	To      time.Time
+	Account string
+	Outcome string
+	Event   string
+	// Limit 为最多返回的条数，<=0 时为 100。
+	Limit int
+}
+
+func (f Filter) match(ev *Event) bool {
+	switch {
+	case !f.From.IsZero() && ev.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && !ev.Time.Before(f.To):
+		return false
+	case f.Account != "" && ev.AccountUID != f.Account:
+		return false
+	case f.Outcome != "" && ev.Outcome != f.Outcome:
+		return false
+	case f.Event != "" && ev.Event != f.Event:
+		return false
+	}
+	return true
+}
+
+// Query 在当前文件与轮转文件中查找符合条件的事件，按时间从新到旧返回。
+func (l *Logger) Query(f Filter) ([]Event, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	var out []Event
+	// 从最新的文件开始读取，凑够条数后即停止
+	for i := 0; i <= l.cfg.MaxBackups && len(out) < f.Limit; i++ {
+		path := l.cfg.Path
+		if i > 0 {
+			path = backupName(l.cfg.Path, i)
+		}
+		events, err := readFile(path, f)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for j := len(events) - 1; j >= 0 && len(out) < f.Limit; j-- {
+			out = append(out, events[j])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return out, nil
+}
+
+func readFile(path string, f Filter) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var events []Event
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		var ev Event
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		if f.match(&ev) {
+			events = append(events, ev)
+		}
+	}
+	return events, sc.Err()
+}
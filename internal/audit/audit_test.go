@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		secrets []string
+		want    string
+	}{
+		{"single", "cookie=abc failed", []string{"abc"}, "cookie=[REDACTED] failed"},
+		{"repeated", "abc/abc", []string{"abc"}, "[REDACTED]/[REDACTED]"},
+		{"longer first", "token abcdef", []string{"abc", "abcdef"}, "token [REDACTED]"},
+		{"empty secret ignored", "abc", []string{""}, "abc"},
+		{"no secrets", "abc", nil, "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in, tt.secrets...); got != tt.want {
+				t.Fatalf("Redact = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashBearer(t *testing.T) {
+	if HashBearer("") != "" {
+		t.Fatal("empty bearer hashed")
+	}
+	h := HashBearer("secret")
+	if len(h) != 16 || h == "secret" || h != HashBearer("secret") || h == HashBearer("other") {
+		t.Fatalf("HashBearer = %q", h)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	l, err := Open(Config{Path: filepath.Join(t.TempDir(), "audit.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: base, Event: EventLogin, AccountUID: "1", Outcome: OutcomeOK},
+		{Time: base.Add(time.Minute), Event: EventLogin, AccountUID: "2", Outcome: OutcomeError},
+		{Time: base.Add(2 * time.Minute), Event: EventCheckNum, AccountUID: "1", Outcome: OutcomeOK},
+		{Time: base.Add(3 * time.Minute), Event: EventLogin, AccountUID: "1", Outcome: OutcomeDenied},
+	}
+	for _, ev := range events {
+		l.Log(ev)
+	}
+
+	tests := []struct {
+		name string
+		f    Filter
+		want []time.Duration // 期望事件相对 base 的时间，从新到旧
+	}{
+		{"all", Filter{}, []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute, 0}},
+		{"account", Filter{Account: "1"}, []time.Duration{3 * time.Minute, 2 * time.Minute, 0}},
+		{"outcome", Filter{Outcome: OutcomeOK}, []time.Duration{2 * time.Minute, 0}},
+		{"event", Filter{Event: EventLogin, Account: "1"}, []time.Duration{3 * time.Minute, 0}},
+		{"range", Filter{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}, []time.Duration{2 * time.Minute, time.Minute}},
+		{"limit", Filter{Limit: 2}, []time.Duration{3 * time.Minute, 2 * time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Query(tt.f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query returned %d events, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, d := range tt.want {
+				if !got[i].Time.Equal(base.Add(d)) {
+					t.Errorf("event %d at %s, want %s", i, got[i].Time, base.Add(d))
+				}
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(Config{Path: path, MaxBytes: 200, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		l.Log(Event{Time: base.Add(time.Duration(i) * time.Second), Event: EventLogin, Outcome: OutcomeOK})
+	}
+
+	for _, name := range []string{path, backupName(path, 1), backupName(path, 2)} {
+		st, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Size() > 200 {
+			t.Errorf("%s is %d bytes, over the limit", name, st.Size())
+		}
+	}
+	if _, err := os.Stat(backupName(path, 3)); !os.IsNotExist(err) {
+		t.Fatalf("backup beyond MaxBackups kept: %v", err)
+	}
+
+	// 查询覆盖轮转文件，最旧的事件随超出的备份一起被丢弃
+	got, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || len(got) >= 10 {
+		t.Fatalf("Query after rotation returned %d events", len(got))
+	}
+	if !got[0].Time.Equal(base.Add(9 * time.Second)) {
+		t.Fatalf("newest event at %s", got[0].Time)
+	}
+	for i := 1; i < len(got); i++ {
+		if !got[i].Time.Equal(got[i-1].Time.Add(-time.Second)) {
+			t.Fatalf("events not contiguous across files: %s after %s", got[i].Time, got[i-1].Time)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Package certs 为 HTTPS 监听提供证书：证书、私钥与客户端 CA 文件变化后在下一次握手时自动重新加载，
+// 更换证书无需重启服务。
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkInterval 为检查文件是否变化的最小间隔。
+const checkInterval = 10 * time.Second
+
+// Options 为证书配置。
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile 非空时校验客户端证书（mTLS）。
+	ClientCAFile string
+	// ClientCertOptional 为 true 时客户端可以不提供证书，提供时仍需通过校验。
+	ClientCertOptional bool
+}
+
+// Reloader 持有当前生效的 tls.Config，并在文件变化时重新加载。
+type Reloader struct {
+	opts Options
+
+	mu      sync.Mutex
+	current *tls.Config
+	stamp   string
+	checked time.Time
+}
+
+// New 加载证书并创建 Reloader；首次加载失败时返回错误。
+func New(opts Options) (*Reloader, error) {
+	r := &Reloader{opts: opts}
+	cfg, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current, r.stamp, r.checked = cfg, r.fileStamp(), time.Now()
+	return r, nil
+}
+
+// TLSConfig 返回用于监听的 tls.Config，每次握手时使用当前证书。
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"http/1.1"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config(), nil
+		},
+	}
+}
+
+func (r *Reloader) config() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) < checkInterval {
+		return r.current
+	}
+	r.checked = time.Now()
+	stamp := r.fileStamp()
+	if stamp == r.stamp {
+		return r.current
+	}
+	cfg, err := r.load()
+	if err != nil {
+		// 证书文件可能正在被替换，保留当前证书，下次检查时重试
+		log.Printf("[tls] reload certificate: %v, keeping current certificate", err)
+		return r.current
+	}
+	r.current, r.stamp = cfg, stamp
+	log.Printf("[tls] certificate reloaded from %s", r.opts.CertFile)
+	return r.current
+}
+
+// fileStamp 以各文件的修改时间与大小标识当前版本。
+func (r *Reloader) fileStamp() string {
+	var b strings.Builder
+	for _, path := range []string{r.opts.CertFile, r.opts.KeyFile, r.opts.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		if fi, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%d:%d;", fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			b.WriteString("missing;")
+		}
+	}
+	return b.String()
+}
+
+func (r *Reloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"http/1.1"},
+		Certificates: []tls.Certificate{cert},
+	}
+	if r.opts.ClientCAFile != "" {
+		pool, err := LoadCertPool(r.opts.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		if r.opts.ClientCertOptional {
+			cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return cfg, nil
+}
+
+// LoadCertPool 从 PEM 文件读取 CA 证书。
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, errors.New("no certificates found in " + path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,336 @@
+// Package config 从 YAML/TOML 文件加载 FunAuth 的配置，并以环境变量覆盖。
+//
+// 每个配置项对应一个 FUNAUTH_* 环境变量（见字段的 env 标签），进程启动时显式设置的变量优先于文件。
+// 各包不读取环境变量，而是由各配置段的转换方法（如 Session.StoreConfig、Proxy.Options）得到自身的配置类型，
+// 再传给对应的构造函数或热更新函数。
+//
+// 带有 reload 标签的配置段或配置项可以在运行时通过 SIGHUP 或文件变更重新加载，其余配置修改后需要重启。
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth"
+	"github.com/Yeah114/FunAuth/internal/audit"
+	"github.com/Yeah114/FunAuth/internal/certs"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+	"github.com/Yeah114/FunAuth/internal/session"
+	"github.com/Yeah114/FunAuth/internal/tenant"
+	"github.com/Yeah114/FunAuth/internal/token"
+)
+
+// Duration 为可从 "30s"、"5m" 等字符串解析的时长。
+type Duration time.Duration
+
+// UnmarshalText 实现 encoding.TextUnmarshaler。
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler。
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// String 返回时长的字符串形式。
+func (d Duration) String() string { return time.Duration(d).String() }
+
+// Config 为全部配置。
+type Config struct {
+	Server    Server    `yaml:"server" toml:"server"`
+	Python3   string    `yaml:"python3" toml:"python3" env:"FUNAUTH_PYTHON3"`
+	Admin     Admin     `yaml:"admin" toml:"admin"`
+	Metrics   Metrics   `yaml:"metrics" toml:"metrics"`
+	Health    Health    `yaml:"health" toml:"health" reload:"true"`
+	Session   Session   `yaml:"session" toml:"session"`
+	Token     Token     `yaml:"token" toml:"token"`
+	Tenants   Tenants   `yaml:"tenants" toml:"tenants" reload:"true"`
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit" reload:"true"`
+	Audit     Audit     `yaml:"audit" toml:"audit"`
+	Proxy     Proxy     `yaml:"proxy" toml:"proxy" reload:"true"`
+	CheckNum  CheckNum  `yaml:"check_num" toml:"check_num"`
+	MCP       MCP       `yaml:"mcp" toml:"mcp"`
+	Skin      Skin      `yaml:"skin" toml:"skin"`
+}
+
+// Server 为 HTTP 服务配置。
+type Server struct {
+	// Addr 为监听地址，多个地址以逗号分隔；"unix:/path/to.sock" 表示 unix 套接字。
+	Addr string `yaml:"addr" toml:"addr" env:"FUNAUTH_ADDR"`
+	// UnixSocketMode 为 unix 套接字文件的权限（八进制），默认 0660。
+	UnixSocketMode string `yaml:"unix_socket_mode" toml:"unix_socket_mode" env:"FUNAUTH_UNIX_SOCKET_MODE"`
+	// ShutdownTimeout 为关闭时等待进行中请求完成的时间，超时后取消这些请求。
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"FUNAUTH_SHUTDOWN_TIMEOUT"`
+	// DrainDelay 为关闭时 /readyz 返回 503 后、停止接受新连接前的等待时间，供负载均衡摘除本实例。
+	DrainDelay Duration `yaml:"drain_delay" toml:"drain_delay" env:"FUNAUTH_SHUTDOWN_DRAIN_DELAY"`
+	// FreePort 为 true 时在监听前结束占用端口的其他 FunAuth 进程。
+	FreePort bool `yaml:"free_port" toml:"free_port" env:"FUNAUTH_FREE_PORT"`
+	// ReusePort 为 true 时以 SO_REUSEPORT 监听，新旧进程可以同时监听同一端口，用于无中断重启。
+	ReusePort bool `yaml:"reuse_port" toml:"reuse_port" env:"FUNAUTH_REUSEPORT"`
+	TLS       TLS  `yaml:"tls" toml:"tls"`
+}
+
+// Addrs 返回全部监听地址。
+func (s Server) Addrs() []string {
+	var addrs []string
+	for _, a := range strings.Split(s.Addr, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
+
+// SocketMode 返回 unix 套接字文件的权限。
+func (s Server) SocketMode() (os.FileMode, error) {
+	if s.UnixSocketMode == "" {
+		return 0o660, nil
+	}
+	v, err := strconv.ParseUint(s.UnixSocketMode, 8, 32)
+	if err != nil || v > 0o777 {
+		return 0, fmt.Errorf("bad unix socket mode %q, want octal like 0660", s.UnixSocketMode)
+	}
+	return os.FileMode(v), nil
+}
+
+// TLS 为 HTTPS 监听配置；配置了证书时启用。
+type TLS struct {
+	// Addr 为 HTTPS 监听地址，与 server.addr 的明文监听同时存在；为空时 server.addr 本身使用 HTTPS。
+	Addr     string `yaml:"addr" toml:"addr" env:"FUNAUTH_TLS_ADDR"`
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"FUNAUTH_TLS_CERT"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"FUNAUTH_TLS_KEY"`
+	// ClientCA 设置后校验客户端证书（mTLS），证书可通过租户的 client_certs 映射到租户。
+	ClientCA string `yaml:"client_ca" toml:"client_ca" env:"FUNAUTH_TLS_CLIENT_CA"`
+	// ClientAuth 为 require（默认，必须提供客户端证书）或 optional（提供时校验）。
+	ClientAuth string `yaml:"client_auth" toml:"client_auth" env:"FUNAUTH_TLS_CLIENT_AUTH"`
+}
+
+// Enabled 报告是否启用 HTTPS。
+func (t TLS) Enabled() bool { return t.CertFile != "" }
+
+// CertOptions 返回证书配置。
+func (t TLS) CertOptions() certs.Options {
+	return certs.Options{
+		CertFile:           t.CertFile,
+		KeyFile:            t.KeyFile,
+		ClientCAFile:       t.ClientCA,
+		ClientCertOptional: t.ClientAuth == "optional",
+	}
+}
+
+// Admin 为管理接口配置。
+type Admin struct {
+	Token string `yaml:"token" toml:"token" env:"FUNAUTH_ADMIN_TOKEN" secret:"true"`
+}
+
+// Metrics 为 /metrics 配置。
+type Metrics struct {
+	Token string `yaml:"token" toml:"token" env:"FUNAUTH_METRICS_TOKEN" secret:"true"`
+}
+
+// Health 为 /readyz 依赖检查配置。
+type Health struct {
+	// TransferMaxAge 为重新拉取失败时，中转服务器目录距最近一次成功拉取仍视为就绪的最长时间，默认 10m。
+	TransferMaxAge Duration `yaml:"transfer_max_age" toml:"transfer_max_age" env:"FUNAUTH_HEALTH_TRANSFER_MAX_AGE"`
+}
+
+// Session 为会话存储配置。
+type Session struct {
+	Backend     string   `yaml:"backend" toml:"backend" env:"FUNAUTH_SESSION_BACKEND"`
+	File        string   `yaml:"file" toml:"file" env:"FUNAUTH_SESSION_FILE"`
+	TTL         Duration `yaml:"ttl" toml:"ttl" env:"FUNAUTH_SESSION_TTL"`
+	IdleTimeout Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"FUNAUTH_SESSION_IDLE_TIMEOUT"`
+	MaxEntries  int      `yaml:"max_entries" toml:"max_entries" env:"FUNAUTH_SESSION_MAX_ENTRIES"`
+}
+
+// StoreConfig 返回会话存储配置。
+func (s Session) StoreConfig() session.Config {
+	return session.Config{
+		Backend:     s.Backend,
+		Path:        s.File,
+		TTL:         time.Duration(s.TTL),
+		IdleTimeout: time.Duration(s.IdleTimeout),
+		MaxEntries:  s.MaxEntries,
+	}
+}
+
+// Token 为 Bearer 令牌配置；Keys 可以热更新，用于不重启地轮换密钥。
+type Token struct {
+	Keys           string   `yaml:"keys" toml:"keys" env:"FUNAUTH_TOKEN_KEYS" secret:"true" reload:"true"`
+	TTL            Duration `yaml:"ttl" toml:"ttl" env:"FUNAUTH_TOKEN_TTL"`
+	RevocationFile string   `yaml:"revocation_file" toml:"revocation_file" env:"FUNAUTH_TOKEN_REVOCATION_FILE"`
+}
+
+// Options 返回令牌签发器配置。
+func (t Token) Options() token.Options {
+	return token.Options{Keys: t.Keys, TTL: time.Duration(t.TTL), RevocationFile: t.RevocationFile}
+}
+
+// Tenants 为租户配置；File 与 List 同时配置时合并。
+type Tenants struct {
+	File string          `yaml:"file" toml:"file" env:"FUNAUTH_TENANTS_FILE"`
+	List []tenant.Tenant `yaml:"list" toml:"list"`
+}
+
+// RateLimit 为限流配置，格式见 ratelimit.ParseSpec。
+type RateLimit struct {
+	IP             string   `yaml:"ip" toml:"ip" env:"FUNAUTH_RATELIMIT_IP"`
+	Bearer         string   `yaml:"bearer" toml:"bearer" env:"FUNAUTH_RATELIMIT_BEARER"`
+	Account        string   `yaml:"account" toml:"account" env:"FUNAUTH_RATELIMIT_ACCOUNT"`
+	AccountMaxWait Duration `yaml:"account_max_wait" toml:"account_max_wait" env:"FUNAUTH_RATELIMIT_ACCOUNT_MAX_WAIT"`
+}
+
+// LoginLimit 返回按账号区分的登录限流参数。
+func (r RateLimit) LoginLimit() auth.LoginLimit {
+	return auth.LoginLimit{Account: r.Account, MaxWait: time.Duration(r.AccountMaxWait)}
+}
+
+// Audit 为审计日志配置。
+type Audit struct {
+	File       string `yaml:"file" toml:"file" env:"FUNAUTH_AUDIT_FILE"`
+	MaxMB      int    `yaml:"max_mb" toml:"max_mb" env:"FUNAUTH_AUDIT_MAX_MB"`
+	MaxBackups int    `yaml:"max_backups" toml:"max_backups" env:"FUNAUTH_AUDIT_MAX_BACKUPS"`
+}
+
+// LoggerConfig 返回审计日志配置；File 为空时表示不启用审计日志。
+func (a Audit) LoggerConfig() audit.Config {
+	return audit.Config{Path: a.File, MaxBytes: int64(a.MaxMB) << 20, MaxBackups: a.MaxBackups}
+}
+
+// Proxy 为代理池配置。
+type Proxy struct {
+	APIURL         string   `yaml:"api_url" toml:"api_url" env:"FUNAUTH_PROXY_API_URL" secret:"true"`
+	ListFile       string   `yaml:"list_file" toml:"list_file" env:"FUNAUTH_PROXY_LIST_FILE"`
+	Scheme         string   `yaml:"scheme" toml:"scheme" env:"FUNAUTH_PROXY_SCHEME"`
+	Rotation       string   `yaml:"rotation" toml:"rotation" env:"FUNAUTH_PROXY_ROTATION"`
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout" env:"FUNAUTH_PROXY_REQUEST_TIMEOUT"`
+	ClientTimeout  Duration `yaml:"client_timeout" toml:"client_timeout" env:"FUNAUTH_PROXY_CLIENT_TIMEOUT"`
+	PoolSize       int      `yaml:"pool_size" toml:"pool_size" env:"FUNAUTH_PROXY_POOL_SIZE"`
+	MaxAge         Duration `yaml:"max_age" toml:"max_age" env:"FUNAUTH_PROXY_MAX_AGE"`
+	BanDuration    Duration `yaml:"ban_duration" toml:"ban_duration" env:"FUNAUTH_PROXY_BAN_DURATION"`
+	MaxFailures    int      `yaml:"max_failures" toml:"max_failures" env:"FUNAUTH_PROXY_MAX_FAILURES"`
+	HealthInterval Duration `yaml:"health_interval" toml:"health_interval" env:"FUNAUTH_PROXY_HEALTH_INTERVAL"`
+	StickyDuration Duration `yaml:"sticky_duration" toml:"sticky_duration" env:"FUNAUTH_PROXY_STICKY_DURATION"`
+	// FailoverRetries 为代理失效时单个请求换代理重试的次数，未设置时为 2，0 表示不重试。
+	FailoverRetries *int `yaml:"failover_retries" toml:"failover_retries" env:"FUNAUTH_PROXY_FAILOVER_RETRIES"`
+	// Fields 为代理池接口 JSON 响应的字段映射，路径写法见 proxy.Mapping。
+	Fields ProxyFields `yaml:"fields" toml:"fields"`
+}
+
+// Options 返回代理池配置。
+func (p Proxy) Options() proxy.Options {
+	return proxy.Options{
+		APIURL:          p.APIURL,
+		ListFile:        p.ListFile,
+		Scheme:          p.Scheme,
+		RequestTimeout:  time.Duration(p.RequestTimeout),
+		ClientTimeout:   time.Duration(p.ClientTimeout),
+		FailoverRetries: p.FailoverRetries,
+		Mapping:         p.Fields.Mapping(),
+		Pool: proxy.PoolOptions{
+			Size:           p.PoolSize,
+			MaxAge:         time.Duration(p.MaxAge),
+			BanDuration:    time.Duration(p.BanDuration),
+			MaxFailures:    p.MaxFailures,
+			HealthInterval: time.Duration(p.HealthInterval),
+			StickyDuration: time.Duration(p.StickyDuration),
+			Rotation:       p.Rotation,
+		},
+	}
+}
+
+// ProxyFields 为代理池接口响应的字段映射，未设置的字段使用默认值。
+type ProxyFields struct {
+	Records      string `yaml:"records" toml:"records" env:"FUNAUTH_PROXY_FIELD_RECORDS"`
+	IP           string `yaml:"ip" toml:"ip" env:"FUNAUTH_PROXY_FIELD_IP"`
+	Port         string `yaml:"port" toml:"port" env:"FUNAUTH_PROXY_FIELD_PORT"`
+	Username     string `yaml:"username" toml:"username" env:"FUNAUTH_PROXY_FIELD_USERNAME"`
+	Password     string `yaml:"password" toml:"password" env:"FUNAUTH_PROXY_FIELD_PASSWORD"`
+	Expiry       string `yaml:"expiry" toml:"expiry" env:"FUNAUTH_PROXY_FIELD_EXPIRY"`
+	Code         string `yaml:"code" toml:"code" env:"FUNAUTH_PROXY_FIELD_CODE"`
+	SuccessCodes string `yaml:"success_codes" toml:"success_codes" env:"FUNAUTH_PROXY_SUCCESS_CODES"`
+	Message      string `yaml:"message" toml:"message" env:"FUNAUTH_PROXY_FIELD_MESSAGE"`
+}
+
+// Mapping 返回字段映射，未设置的字段由 proxy 包补全为默认值。
+func (f ProxyFields) Mapping() proxy.Mapping {
+	return proxy.Mapping{
+		Records:      f.Records,
+		IP:           f.IP,
+		Port:         f.Port,
+		Username:     f.Username,
+		Password:     f.Password,
+		Expiry:       f.Expiry,
+		Code:         f.Code,
+		SuccessCodes: f.SuccessCodes,
+		Message:      f.Message,
+	}
+}
+
+// CheckNum 为校验值生成配置。
+type CheckNum struct {
+	Backend     string   `yaml:"backend" toml:"backend" env:"FUNAUTH_CHECKNUM_BACKEND"`
+	WorkerCmd   string   `yaml:"worker_cmd" toml:"worker_cmd" env:"FUNAUTH_CHECKNUM_WORKER_CMD"`
+	PoolSize    int      `yaml:"pool_size" toml:"pool_size" env:"FUNAUTH_CHECKNUM_POOL_SIZE"`
+	PoolTimeout Duration `yaml:"pool_timeout" toml:"pool_timeout" env:"FUNAUTH_CHECKNUM_POOL_TIMEOUT"`
+	// CacheSize 为 nil 时使用默认值，0 表示关闭缓存。
+	CacheSize *int   `yaml:"cache_size" toml:"cache_size" env:"FUNAUTH_CHECKNUM_CACHE_SIZE"`
+	CacheFile string `yaml:"cache_file" toml:"cache_file" env:"FUNAUTH_CHECKNUM_CACHE_FILE"`
+}
+
+// MCP 为 dynamic MCP 反编译接口配置。
+type MCP struct {
+	MaxBytes         int      `yaml:"max_bytes" toml:"max_bytes" env:"FUNAUTH_MCP_MAX_BYTES"`
+	DecompileTimeout Duration `yaml:"decompile_timeout" toml:"decompile_timeout" env:"FUNAUTH_MCP_DECOMPILE_TIMEOUT"`
+	// DecompileConcurrency 为同时运行的反编译进程数上限，超时的进程在真正结束前仍占用名额。
+	DecompileConcurrency int `yaml:"decompile_concurrency" toml:"decompile_concurrency" env:"FUNAUTH_MCP_DECOMPILE_CONCURRENCY"`
+}
+
+// Skin 为皮肤配置。
+type Skin struct {
+	// DefaultItemID 为账号未设置皮肤时使用的皮肤，为空时保持内置默认值。
+	DefaultItemID string `yaml:"default_item_id" toml:"default_item_id"`
+}
+
+// ItemID 返回账号未设置皮肤时使用的皮肤。
+func (s Skin) ItemID() string {
+	if s.DefaultItemID != "" {
+		return s.DefaultItemID
+	}
+	return auth.DefaultSkinItemID
+}
+
+// AuthOptions 返回 auth 包的运行参数。
+func (c *Config) AuthOptions() auth.Options {
+	return auth.Options{
+		Python3: c.Python3,
+		CheckNum: auth.CheckNumOptions{
+			Backend:     c.CheckNum.Backend,
+			WorkerCmd:   strings.Fields(c.CheckNum.WorkerCmd),
+			PoolSize:    c.CheckNum.PoolSize,
+			PoolTimeout: time.Duration(c.CheckNum.PoolTimeout),
+			CacheSize:   c.CheckNum.CacheSize,
+			CacheFile:   c.CheckNum.CacheFile,
+		},
+		MCPDecompileConcurrency: c.MCP.DecompileConcurrency,
+	}
+}
+
+// Default 返回默认配置；未列出的项由各包使用自身的默认值。
+func Default() *Config {
+	return &Config{Server: Server{Addr: ":8080", ShutdownTimeout: Duration(30 * time.Second), DrainDelay: Duration(5 * time.Second)}}
+}
+
+// String 返回用于日志的简要描述。
+func (c *Config) String() string {
+	return fmt.Sprintf("addr=%s tenants=%d proxy=%t", c.Server.Addr, len(c.Tenants.List), c.Proxy.APIURL != "")
+}
@@ -0,0 +1,334 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Yeah114/FunAuth/internal/certs"
+	"github.com/Yeah114/FunAuth/internal/proxy"
+	"github.com/Yeah114/FunAuth/internal/ratelimit"
+	"github.com/Yeah114/FunAuth/internal/session"
+	"github.com/Yeah114/FunAuth/internal/tenant"
+	"github.com/Yeah114/FunAuth/internal/token"
+)
+
+// operatorEnv 为进程启动时由运维显式设置的 FUNAUTH_* 变量，优先级高于配置文件。
+// 在包初始化时记录，重新加载配置时使用同一份记录。
+var operatorEnv = func() map[string]string {
+	m := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, "FUNAUTH_") && v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}()
+
+// Load 读取配置文件（path 为空时只使用默认值与环境变量），再以显式设置的环境变量覆盖。
+// 文件格式按扩展名判断：.yaml/.yml 或 .toml。
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := decode(path, raw, cfg); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func decode(path string, raw []byte, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		// 空文件返回 io.EOF，视为全部使用默认值
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		return dec.Decode(cfg)
+	default:
+		return fmt.Errorf("unsupported config format %q, want .yaml, .yml or .toml", filepath.Ext(path))
+	}
+}
+
+// field 为带 env 标签的配置项。
+type field struct {
+	env    string
+	path   string // 如 "proxy.api_url"
+	reload bool
+	secret bool
+	value  reflect.Value
+}
+
+// fields 返回 cfg 中全部带 env 标签的配置项。
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string, reload bool)
+	walk = func(v reflect.Value, prefix string, reload bool) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			r := reload || sf.Tag.Get("reload") == "true"
+			if env := sf.Tag.Get("env"); env != "" {
+				out = append(out, field{env: env, path: path, reload: r, secret: sf.Tag.Get("secret") == "true", value: v.Field(i)})
+				continue
+			}
+			if sf.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path, r)
+			}
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "", false)
+	return out
+}
+
+func applyEnv(cfg *Config) error {
+	var errs []error
+	for _, f := range fields(cfg) {
+		raw, ok := operatorEnv[f.env]
+		if !ok {
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		v.SetBool(raw == "1" || strings.EqualFold(raw, "true"))
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(&n))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+// String 返回配置项的环境变量形式，未设置时返回空串。
+func (f field) String() string {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		if v.Int() == 0 {
+			return ""
+		}
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.String:
+		return v.String()
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			return "1"
+		}
+		return ""
+	case v.Kind() == reflect.Int:
+		if v.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return strconv.FormatInt(v.Elem().Int(), 10)
+	}
+	return ""
+}
+
+// Setting 为一项配置的展示形式。
+type Setting struct {
+	Path   string
+	Env    string
+	Value  string
+	Reload bool
+}
+
+// Settings 返回全部配置项，密钥类配置只显示是否已设置。
+func (c *Config) Settings() []Setting {
+	var out []Setting
+	for _, f := range fields(c) {
+		v := f.String()
+		if f.secret && v != "" {
+			v = "<set>"
+		}
+		out = append(out, Setting{Path: f.path, Env: f.env, Value: v, Reload: f.reload})
+	}
+	return out
+}
+
+// RestartRequired 返回与 old 相比发生了变化、但不能热更新的配置项。
+func (c *Config) RestartRequired(old *Config) []string {
+	var changed []string
+	oldFields := fields(old)
+	for i, f := range fields(c) {
+		if !f.reload && f.String() != oldFields[i].String() {
+			changed = append(changed, f.path)
+		}
+	}
+	if c.Skin != old.Skin {
+		changed = append(changed, "skin.default_item_id")
+	}
+	return changed
+}
+
+// TenantList 返回合并后的租户列表：先读取 tenants.file，再追加 tenants.list。
+func (c *Config) TenantList() ([]tenant.Tenant, error) {
+	var list []tenant.Tenant
+	if c.Tenants.File != "" {
+		loaded, err := tenant.Load(c.Tenants.File)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, loaded...)
+	}
+	return append(list, c.Tenants.List...), nil
+}
+
+// Validate 检查配置，返回全部错误。
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(path string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	oneOf := func(path, v string, allowed ...string) {
+		if v == "" {
+			return
+		}
+		for _, a := range allowed {
+			if v == a {
+				return
+			}
+		}
+		add(path, fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), v))
+	}
+
+	addrs := c.Server.Addrs()
+	if len(addrs) == 0 {
+		add("server.addr", errors.New("must not be empty"))
+	}
+	for _, a := range addrs {
+		if path, ok := strings.CutPrefix(a, "unix:"); ok && path == "" {
+			add("server.addr", fmt.Errorf("%q: empty unix socket path", a))
+		}
+	}
+	if _, err := c.Server.SocketMode(); err != nil {
+		add("server.unix_socket_mode", err)
+	}
+	for _, f := range fields(c) {
+		if f.value.Type() == durationType && f.value.Int() < 0 {
+			add(f.path, errors.New("must not be negative"))
+		}
+		if f.value.Kind() == reflect.Int && f.value.Int() < 0 {
+			add(f.path, errors.New("must not be negative"))
+		}
+	}
+
+	if tc := c.Server.TLS; tc.Enabled() || tc.KeyFile != "" || tc.Addr != "" || tc.ClientCA != "" {
+		switch {
+		case tc.CertFile == "" || tc.KeyFile == "":
+			add("server.tls", errors.New("cert_file and key_file must both be set"))
+		case tc.ClientAuth != "" && tc.ClientCA == "":
+			add("server.tls.client_auth", errors.New("requires client_ca"))
+		default:
+			_, err := certs.New(tc.CertOptions())
+			add("server.tls", err)
+		}
+		oneOf("server.tls.client_auth", tc.ClientAuth, "require", "optional")
+	}
+	oneOf("session.backend", c.Session.Backend, session.BackendMemory, session.BackendFile)
+	if c.Token.Keys != "" {
+		_, err := token.ParseKeyRing(c.Token.Keys)
+		add("token.keys", err)
+	}
+	if list, err := c.TenantList(); err != nil {
+		add("tenants", err)
+	} else if len(list) > 0 {
+		_, err := tenant.NewRegistry(list)
+		add("tenants", err)
+	}
+	for _, spec := range []struct{ path, value string }{
+		{"rate_limit.ip", c.RateLimit.IP},
+		{"rate_limit.bearer", c.RateLimit.Bearer},
+		{"rate_limit.account", c.RateLimit.Account},
+	} {
+		_, err := ratelimit.ParseSpec(spec.value)
+		add(spec.path, err)
+	}
+	if c.Proxy.APIURL != "" {
+		if u, err := url.Parse(c.Proxy.APIURL); err != nil {
+			add("proxy.api_url", err)
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			add("proxy.api_url", fmt.Errorf("unsupported scheme %q", u.Scheme))
+		}
+	}
+	oneOf("proxy.scheme", c.Proxy.Scheme, "http", "https", "socks5", "socks5h")
+	oneOf("proxy.rotation", c.Proxy.Rotation, proxy.RotateRoundRobin, proxy.RotateRandom, proxy.RotateFailover)
+	if c.Proxy.ListFile != "" {
+		scheme := c.Proxy.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		_, err := proxy.LoadList(c.Proxy.ListFile, scheme)
+		add("proxy.list_file", err)
+	}
+	oneOf("check_num.backend", c.CheckNum.Backend, "python", "pool")
+	if c.CheckNum.CacheSize != nil && *c.CheckNum.CacheSize < 0 {
+		add("check_num.cache_size", errors.New("must not be negative"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withEnv 在测试期间替换启动时记录的运维环境变量。
+func withEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	saved := operatorEnv
+	operatorEnv = env
+	t.Cleanup(func() { operatorEnv = saved })
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const (
+	yamlConfig = `
+server:
+  addr: ":9000"
+  shutdown_timeout: 10s
+proxy:
+  pool_size: 4
+  failover_retries: 0
+rate_limit: { ip: "5/s:5" }
+`
+	tomlConfig = `
+[server]
+addr = ":9000"
+shutdown_timeout = "10s"
+
+[proxy]
+pool_size = 4
+failover_retries = 0
+
+[rate_limit]
+ip = "5/s:5"
+`
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string // 文件名，为空时不使用配置文件
+		body  string
+		env   map[string]string
+		check func(t *testing.T, c *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":8080" || time.Duration(c.Server.ShutdownTimeout) != 30*time.Second {
+					t.Errorf("server = %+v", c.Server)
+				}
+				if c.Proxy.FailoverRetries != nil {
+					t.Errorf("failover_retries = %d, want unset", *c.Proxy.FailoverRetries)
+				}
+			},
+		},
+		{
+			name: "env over defaults",
+			env:  map[string]string{"FUNAUTH_ADDR": ":7000", "FUNAUTH_PROXY_FAILOVER_RETRIES": "3", "FUNAUTH_FREE_PORT": "true"},
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":7000" || !c.Server.FreePort {
+					t.Errorf("server = %+v", c.Server)
+				}
+				if c.Proxy.FailoverRetries == nil || *c.Proxy.FailoverRetries != 3 {
+					t.Errorf("failover_retries = %v, want 3", c.Proxy.FailoverRetries)
+				}
+			},
+		},
+		{
+			name: "yaml over defaults",
+			file: "funauth.yaml", body: yamlConfig,
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":9000" || time.Duration(c.Server.ShutdownTimeout) != 10*time.Second {
+					t.Errorf("server = %+v", c.Server)
+				}
+				if time.Duration(c.Server.DrainDelay) != 5*time.Second {
+					t.Errorf("drain_delay = %s, want the default", time.Duration(c.Server.DrainDelay))
+				}
+				// 文件中显式的 0 与未设置不同
+				if c.Proxy.FailoverRetries == nil || *c.Proxy.FailoverRetries != 0 {
+					t.Errorf("failover_retries = %v, want 0", c.Proxy.FailoverRetries)
+				}
+			},
+		},
+		{
+			name: "toml over defaults",
+			file: "funauth.toml", body: tomlConfig,
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":9000" || c.Proxy.PoolSize != 4 || c.RateLimit.IP != "5/s:5" {
+					t.Errorf("config = %+v", c)
+				}
+			},
+		},
+		{
+			name: "env over yaml",
+			file: "funauth.yml", body: yamlConfig,
+			env: map[string]string{"FUNAUTH_ADDR": ":7000", "FUNAUTH_SHUTDOWN_TIMEOUT": "1m", "FUNAUTH_PROXY_FAILOVER_RETRIES": "2"},
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":7000" || time.Duration(c.Server.ShutdownTimeout) != time.Minute {
+					t.Errorf("server = %+v", c.Server)
+				}
+				if *c.Proxy.FailoverRetries != 2 {
+					t.Errorf("failover_retries = %d, want 2", *c.Proxy.FailoverRetries)
+				}
+				if c.Proxy.PoolSize != 4 {
+					t.Errorf("pool_size = %d, file value lost", c.Proxy.PoolSize)
+				}
+			},
+		},
+		{
+			name: "env over toml",
+			file: "funauth.toml", body: tomlConfig,
+			env: map[string]string{"FUNAUTH_RATELIMIT_IP": "off"},
+			check: func(t *testing.T, c *Config) {
+				if c.RateLimit.IP != "off" || c.Server.Addr != ":9000" {
+					t.Errorf("config = %+v", c)
+				}
+			},
+		},
+		{
+			name: "empty yaml",
+			file: "empty.yaml",
+			check: func(t *testing.T, c *Config) {
+				if c.Server.Addr != ":8080" {
+					t.Errorf("addr = %q", c.Server.Addr)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnv(t, tt.env)
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, tt.file, tt.body)
+			}
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		body    string
+		env     map[string]string
+		wantErr []string
+	}{
+		{"unknown yaml field", "c.yaml", "server: { adr: x }", nil, []string{"adr"}},
+		{"unknown toml field", "c.toml", "[server]\nadr = \"x\"", nil, []string{"missing in the target struct"}},
+		{"unsupported format", "c.json", "{}", nil, []string{"unsupported config format"}},
+		{
+			name:    "bad env values reported together",
+			env:     map[string]string{"FUNAUTH_SESSION_TTL": "soon", "FUNAUTH_PROXY_POOL_SIZE": "many"},
+			wantErr: []string{"FUNAUTH_SESSION_TTL", "FUNAUTH_PROXY_POOL_SIZE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnv(t, tt.env)
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, tt.file, tt.body)
+			}
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load succeeded")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr []string
+	}{
+		{"defaults", nil, nil},
+		{"negative duration", map[string]string{"FUNAUTH_SESSION_TTL": "-1s"}, []string{"session.ttl"}},
+		{"bad backend", map[string]string{"FUNAUTH_SESSION_BACKEND": "redis"}, []string{"session.backend"}},
+		{"bad rate", map[string]string{"FUNAUTH_RATELIMIT_IP": "fast"}, []string{"rate_limit.ip"}},
+		{"bad proxy api", map[string]string{"FUNAUTH_PROXY_API_URL": "ftp://x"}, []string{"proxy.api_url"}},
+		{
+			name:    "all errors reported",
+			env:     map[string]string{"FUNAUTH_TOKEN_KEYS": "k1:short", "FUNAUTH_PROXY_SCHEME": "ftp", "FUNAUTH_TLS_KEY": "key.pem"},
+			wantErr: []string{"token.keys", "proxy.scheme", "server.tls"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnv(t, tt.env)
+			c, err := Load("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate succeeded")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{"unchanged", nil, nil},
+		{"reloadable section", map[string]string{"FUNAUTH_PROXY_POOL_SIZE": "9", "FUNAUTH_RATELIMIT_IP": "off"}, nil},
+		{"reloadable field", map[string]string{"FUNAUTH_TOKEN_KEYS": "k2:abcdefabcdefabcdefab"}, nil},
+		{"restart", map[string]string{"FUNAUTH_ADDR": ":7000", "FUNAUTH_TOKEN_TTL": "1h"}, []string{"server.addr", "token.ttl"}},
+	}
+	withEnv(t, nil)
+	old, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnv(t, tt.env)
+			c, err := Load("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.RestartRequired(old); !slices.Equal(got, tt.want) {
+				t.Fatalf("RestartRequired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsHideSecrets(t *testing.T) {
+	withEnv(t, map[string]string{"FUNAUTH_ADMIN_TOKEN": "hunter2", "FUNAUTH_ADDR": ":7000"})
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range c.Settings() {
+		switch s.Env {
+		case "FUNAUTH_ADMIN_TOKEN":
+			if s.Value != "<set>" {
+				t.Errorf("admin token shown as %q", s.Value)
+			}
+		case "FUNAUTH_ADDR":
+			if s.Value != ":7000" {
+				t.Errorf("addr shown as %q", s.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// Watch 每隔 interval 检查 path 的内容，发生变化时调用 onChange，直到 ctx 取消。
+// 文件暂时不可读（例如编辑器替换文件的过程中）时跳过本次检查。
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	last, _ := fileSum(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sum, err := fileSum(path)
+		if err != nil || sum == last {
+			continue
+		}
+		last = sum
+		onChange()
+	}
+}
+
+func fileSum(path string) ([sha256.Size]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(raw), nil
+}
@@ -0,0 +1,132 @@
+// Package health 并发执行一组依赖检查并汇总结果，供就绪探针使用。
+//
+// 每项检查的结果会缓存 TTL，避免探针频繁访问上游（例如校验 Cookie 需要真实登录）。
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 检查状态。
+const (
+	StatusOK      = "ok"
+	StatusFail    = "fail"
+	StatusSkipped = "skipped"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultTTL     = 30 * time.Second
+)
+
+// ErrSkipped 由检查函数返回，表示该依赖未启用，不影响就绪状态。
+var ErrSkipped = errors.New("check skipped")
+
+// Check 为一项依赖检查。
+type Check struct {
+	Name string
+	// Run 执行检查，返回的 detail 会原样出现在结果中。
+	Run func(ctx context.Context) (detail string, err error)
+	// Timeout 为单次检查超时，<=0 时为 10s。
+	Timeout time.Duration
+	// TTL 为结果缓存时间，<=0 时为 30s。
+	TTL time.Duration
+}
+
+// Result 为一项检查的结果。
+type Result struct {
+	Status    string    `json:"status"`
+	LatencyMs int64     `json:"latency_ms"`
+	Detail    string    `json:"detail,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report 为全部检查的汇总；任一检查失败时 Status 为 fail。
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type entry struct {
+	check Check
+	mu    sync.Mutex // 串行化同一检查的执行，并发请求复用同一次结果
+	last  Result
+}
+
+// Checker 执行并缓存一组检查。
+type Checker struct {
+	entries []*entry
+}
+
+// NewChecker 创建检查器。
+func NewChecker(checks ...Check) *Checker {
+	c := &Checker{}
+	for _, ch := range checks {
+		if ch.Timeout <= 0 {
+			ch.Timeout = defaultTimeout
+		}
+		if ch.TTL <= 0 {
+			ch.TTL = defaultTTL
+		}
+		c.entries = append(c.entries, &entry{check: ch})
+	}
+	return c
+}
+
+// Run 并发执行全部检查（命中缓存的直接返回缓存结果）。
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.entries))
+	var wg sync.WaitGroup
+	for i, e := range c.entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = e.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	rep := Report{Status: StatusOK, Checks: make(map[string]Result, len(results))}
+	for i, e := range c.entries {
+		rep.Checks[e.check.Name] = results[i]
+		if results[i].Status == StatusFail {
+			rep.Status = StatusFail
+		}
+	}
+	return rep
+}
+
+func (e *entry) run(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.last.CheckedAt.IsZero() && time.Since(e.last.CheckedAt) < e.check.TTL {
+		return e.last
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, e.check.Timeout)
+	defer cancel()
+	start := time.Now()
+	detail, err := e.check.Run(checkCtx)
+	res := Result{
+		Status:    StatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Detail:    detail,
+		CheckedAt: start.UTC(),
+	}
+	switch {
+	case errors.Is(err, ErrSkipped):
+		res.Status = StatusSkipped
+	case err != nil:
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	// 调用方取消导致的失败不缓存，下一次探针重新检查
+	if ctx.Err() == nil || res.Status == StatusOK {
+		e.last = res
+	}
+	return res
+}
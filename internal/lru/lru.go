@@ -0,0 +1,220 @@
+// Package lru 提供一个并发安全、容量有界的字符串 LRU 缓存，可选持久化到磁盘。
+package lru
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats 为缓存命中统计。
+type Stats struct {
+	Size     int   `json:"size"`
+	Capacity int   `json:"capacity"`
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+}
+
+type entry struct {
+	Key   string `json:"k"`
+	Value string `json:"v"`
+}
+
+// Cache 为字符串 LRU 缓存。
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	// version 在每次 Put 时递增，saved 为最近一次成功写入磁盘的版本，二者不等即有未保存的变更。
+	version uint64
+	saved   uint64
+
+	// saveMu 串行化 Save，避免较旧的快照覆盖较新的文件。
+	saveMu sync.Mutex
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// New 创建容量为 capacity 的缓存，capacity 必须大于 0。
+func New(capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Cache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+// Get 查找 key，并将其标记为最近使用。
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return "", false
+	}
+	c.hits.Add(1)
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry).Value, true
+}
+
+// Put 写入 key，超出容量时淘汰最久未使用的条目。
+func (c *Cache) Put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(key, value)
+	c.version++
+}
+
+func (c *Cache) put(key, value string) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry).Value = value
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry{Key: key, Value: value})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).Key)
+	}
+}
+
+// Len 返回当前条目数。
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Stats 返回命中统计。
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Size:     c.Len(),
+		Capacity: c.capacity,
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+	}
+}
+
+// Load 从 path 读取之前保存的条目，文件不存在时不视为错误。
+func (c *Cache) Load(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var entries []entry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 文件中按最近使用在前的顺序保存，倒序写入以还原顺序
+	for i := len(entries) - 1; i >= 0; i-- {
+		c.put(entries[i].Key, entries[i].Value)
+	}
+	return nil
+}
+
+// Save 将当前条目原子地写入 path；只有写入成功后才会清除未保存标记。
+func (c *Cache) Save(path string) error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.Lock()
+	entries := make([]entry, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		entries = append(entries, *el.Value.(*entry))
+	}
+	version := c.version
+	c.mu.Unlock()
+
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	c.mu.Lock()
+	c.saved = version
+	c.mu.Unlock()
+	return nil
+}
+
+// Dirty 报告是否有尚未写入磁盘的变更。
+func (c *Cache) Dirty() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version != c.saved
+}
+
+// Persist 从 path 加载条目，并每隔 interval 在有变更时写回。
+//
+// 加载失败（如文件损坏）时仍会启动定时写回，返回的错误只表示旧条目未能恢复。
+// stop 结束定时写回并在有变更时最后保存一次，可重复调用。
+func (c *Cache) Persist(path string, interval time.Duration) (stop func() error, err error) {
+	err = c.Load(path)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if !c.Dirty() {
+				continue
+			}
+			if err := c.Save(path); err != nil {
+				log.Printf("[lru] save %s: %v", path, err)
+			}
+		}
+	}()
+	var once sync.Once
+	stop = func() error {
+		once.Do(func() { close(done) })
+		<-exited
+		if !c.Dirty() {
+			return nil
+		}
+		return c.Save(path)
+	}
+	return stop, err
+}
@@ -0,0 +1,139 @@
+package lru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []string // "put:x" 或 "get:x"
+		want []string
+		gone []string
+	}{
+		{"oldest evicted", []string{"put:a", "put:b", "put:c"}, []string{"b", "c"}, []string{"a"}},
+		{"get protects", []string{"put:a", "put:b", "get:a", "put:c"}, []string{"a", "c"}, []string{"b"}},
+		{"overwrite protects", []string{"put:a", "put:b", "put:a", "put:c"}, []string{"a", "c"}, []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(2)
+			for _, op := range tt.ops {
+				switch key := op[4:]; op[:4] {
+				case "put:":
+					c.Put(key, "v"+key)
+				case "get:":
+					c.Get(key)
+				}
+			}
+			for _, k := range tt.want {
+				if v, ok := c.Get(k); !ok || v != "v"+k {
+					t.Errorf("Get(%q) = %q, %v", k, v, ok)
+				}
+			}
+			for _, k := range tt.gone {
+				if _, ok := c.Get(k); ok {
+					t.Errorf("Get(%q) found evicted entry", k)
+				}
+			}
+			if c.Len() != len(tt.want) {
+				t.Fatalf("Len = %d, want %d", c.Len(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := New(0)
+	c.Put("a", "1")
+	c.Get("a")
+	c.Get("b")
+	c.Get("a")
+	want := Stats{Size: 1, Capacity: 1, Hits: 2, Misses: 1}
+	if got := c.Stats(); got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestSaveLoadKeepsOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := New(3)
+	for _, k := range []string{"a", "b", "c"} {
+		c.Put(k, "v"+k)
+	}
+	c.Get("a") // 最近使用顺序：a c b
+	if !c.Dirty() {
+		t.Fatal("not dirty after Put")
+	}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.Dirty() {
+		t.Fatal("dirty after Save")
+	}
+
+	loaded := New(3)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Put("d", "vd") // 应淘汰最久未使用的 b
+	if _, ok := loaded.Get("b"); ok {
+		t.Fatal("recency order lost across Save/Load")
+	}
+	for _, k := range []string{"a", "c", "d"} {
+		if _, ok := loaded.Get(k); !ok {
+			t.Errorf("Get(%q) missing after Load", k)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), false},
+		{"corrupt file", bad, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(1).Load(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPersistSavesOnStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := New(2)
+	stop, err := c.Persist(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("a", "1")
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("second stop = %v", err)
+	}
+
+	reloaded := New(2)
+	if err := reloaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := reloaded.Get("a"); !ok || v != "1" {
+		t.Fatalf("Get after Persist stop = %q, %v", v, ok)
+	}
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+		want  string
+	}{
+		{
+			name: "counter",
+			setup: func(r *Registry) {
+				c := r.NewCounterVec("funauth_logins_total", "Logins.", "outcome")
+				c.Inc("ok")
+				c.Add(2, "error")
+				c.Inc("ok")
+			},
+			want: `# HELP funauth_logins_total Logins.
+# TYPE funauth_logins_total counter
+funauth_logins_total{outcome="error"} 2
+funauth_logins_total{outcome="ok"} 2
+`,
+		},
+		{
+			name: "histogram",
+			setup: func(r *Registry) {
+				h := r.NewHistogramVec("funauth_seconds", "Latency.", []float64{1, 0.1}, "route")
+				h.Observe(0.05, "/a")
+				h.Observe(0.1, "/a")
+				h.Observe(0.5, "/a")
+				h.Observe(3, "/a")
+			},
+			want: `# HELP funauth_seconds Latency.
+# TYPE funauth_seconds histogram
+funauth_seconds_bucket{route="/a",le="0.1"} 2
+funauth_seconds_bucket{route="/a",le="1"} 3
+funauth_seconds_bucket{route="/a",le="+Inf"} 4
+funauth_seconds_sum{route="/a"} 3.65
+funauth_seconds_count{route="/a"} 4
+`,
+		},
+		{
+			name: "gauge",
+			setup: func(r *Registry) {
+				r.NewGaugeFunc("funauth_unset", "Not set yet.", nil)
+				g := r.NewGaugeFunc("funauth_inf", "Infinite.", nil)
+				g.Set(func() float64 { return math.Inf(1) })
+			},
+			want: `# HELP funauth_inf Infinite.
+# TYPE funauth_inf gauge
+funauth_inf +Inf
+`,
+		},
+		{
+			name: "escaping",
+			setup: func(r *Registry) {
+				r.NewCounterVec("funauth_escape_total", "Line one\nback\\slash.", "v").Inc("a\"b\\c\nd")
+			},
+			want: `# HELP funauth_escape_total Line one\nback\\slash.
+# TYPE funauth_escape_total counter
+funauth_escape_total{v="a\"b\\c\nd"} 1
+`,
+		},
+		{
+			name: "sorted by name",
+			setup: func(r *Registry) {
+				r.NewCounterVec("funauth_b_total", "B.").Inc()
+				r.NewCounterVec("funauth_a_total", "A.").Inc()
+			},
+			want: `# HELP funauth_a_total A.
+# TYPE funauth_a_total counter
+funauth_a_total 1
+# HELP funauth_b_total B.
+# TYPE funauth_b_total counter
+funauth_b_total 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+			var sb strings.Builder
+			if err := r.WritePrometheus(&sb); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Fatalf("output:\n%s\nwant:\n%s", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+	}{
+		{"duplicate name", func(r *Registry) {
+			r.NewCounterVec("x_total", "X.")
+			r.NewGaugeFunc("x_total", "X.", nil)
+		}},
+		{"label count", func(r *Registry) {
+			r.NewCounterVec("y_total", "Y.", "a", "b").Inc("only-one")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("no panic")
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// sticky 为账号与固定代理的绑定。
+type sticky struct {
+	key   string // 代理的 Key()
+	until time.Time
+}
+
+// accountRef 保存一次请求所属的账号，创建客户端时账号可能尚未确定（需先完成 Cookie 认证）。
+type accountRef struct {
+	uid atomic.Pointer[string]
+}
+
+func (a *accountRef) get() string {
+	if v := a.uid.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+type accountKey struct{}
+
+// WithAccount 返回携带账号 uid 的 ctx；以它创建的客户端使用该账号的固定代理。
+// uid 可以为空，待认证完成后由 SetAccount 设置。
+func WithAccount(ctx context.Context, uid string) context.Context {
+	a := &accountRef{}
+	a.uid.Store(&uid)
+	return context.WithValue(ctx, accountKey{}, a)
+}
+
+// SetAccount 设置 ctx（须由 WithAccount 创建）所属的账号，已创建的客户端之后的请求改用该账号的固定代理。
+// ctx 未携带账号时返回 false。
+func SetAccount(ctx context.Context, uid string) bool {
+	a, ok := ctx.Value(accountKey{}).(*accountRef)
+	if ok {
+		a.uid.Store(&uid)
+	}
+	return ok
+}
+
+func accountFrom(ctx context.Context) *accountRef {
+	a, _ := ctx.Value(accountKey{}).(*accountRef)
+	return a
+}
+
+// AcquireFor 返回账号 account 的固定代理；没有固定代理或固定代理已被封禁、过期时取得新的代理并绑定。
+// account 为空时等同于 Acquire。
+func (p *Pool) AcquireFor(ctx context.Context, account string) (*Proxy, error) {
+	if account == "" {
+		return p.Acquire(ctx)
+	}
+	p.mu.Lock()
+	px := p.stickyLocked(account, p.now())
+	p.mu.Unlock()
+	if px != nil {
+		return px, nil
+	}
+	px, err := p.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.Stick(account, px), nil
+}
+
+// Stick 返回账号 account 仍然可用的固定代理；没有时把 px 绑定为该账号的固定代理并返回 px。
+func (p *Pool) Stick(account string, px *Proxy) *Proxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if cur := p.stickyLocked(account, now); cur != nil {
+		return cur
+	}
+	p.sticky[account] = sticky{key: px.Key(), until: now.Add(p.opts.StickyDuration)}
+	log.Printf("[proxy] account %s pinned to %s", account, px)
+	return px
+}
+
+// stickyLocked 返回账号仍在缓存中（未封禁、未过期）的固定代理并延长绑定时间，没有时返回 nil。
+func (p *Pool) stickyLocked(account string, now time.Time) *Proxy {
+	s, ok := p.sticky[account]
+	if !ok {
+		return nil
+	}
+	p.pruneLocked(now)
+	i := p.indexLocked(s.key)
+	if i < 0 || now.After(s.until) {
+		delete(p.sticky, account)
+		return nil
+	}
+	px := p.proxies[i]
+	px.lastUsed = now
+	p.sticky[account] = sticky{key: s.key, until: now.Add(p.opts.StickyDuration)}
+	return px
+}
@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStickyProxy(t *testing.T) {
+	const account = "10001"
+	hosts := []string{"1.1.1.1:80", "2.2.2.2:80"}
+	type step struct {
+		after time.Duration // 距上一步的时间
+		op    string        // "acquire"：为账号取代理；"ban"：封禁账号当前的代理
+		same  bool          // acquire 是否应返回首次绑定的代理
+	}
+	tests := []struct {
+		name    string
+		expires time.Duration // 来源为首个代理（即首次绑定的代理）给出的有效期，0 表示未给出
+		steps   []step
+	}{
+		{
+			name: "renewed on use",
+			steps: []step{
+				{20 * time.Minute, "acquire", true},
+				{20 * time.Minute, "acquire", true},
+				{29 * time.Minute, "acquire", true},
+			},
+		},
+		{
+			name:  "binding expires when idle",
+			steps: []step{{31 * time.Minute, "acquire", false}},
+		},
+		{
+			name: "banned proxy replaced",
+			steps: []step{
+				{0, "ban", false},
+				{0, "acquire", false},
+			},
+		},
+		{
+			// 未给出过期时间的固定代理超过 MaxAge 后仍供该账号使用
+			name:  "pinned proxy outlives max age",
+			steps: []step{{6 * time.Minute, "acquire", true}},
+		},
+		{
+			name:    "source expiry always wins",
+			expires: 2 * time.Minute,
+			steps:   []step{{2 * time.Minute, "acquire", false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			src := &testSource{hosts: hosts}
+			if tt.expires > 0 {
+				src.expires = map[string]time.Time{hosts[0]: now.Add(tt.expires)}
+			}
+			p := newTestPool(t, src, PoolOptions{Size: len(hosts), MaxAge: 5 * time.Minute, StickyDuration: 30 * time.Minute}, &now)
+			pinned, err := p.AcquireFor(context.Background(), account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pinned.URL.Host != hosts[0] {
+				t.Fatalf("first binding = %s, want %s", pinned.URL.Host, hosts[0])
+			}
+			cur := pinned
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				switch s.op {
+				case "ban":
+					p.Ban(cur, BanConnection)
+				case "acquire":
+					if cur, err = p.AcquireFor(context.Background(), account); err != nil {
+						t.Fatalf("step %d: %v", i, err)
+					}
+					if (cur == pinned) != s.same {
+						t.Fatalf("step %d: got %s, pinned %s, want same=%v", i, cur.Key(), pinned.Key(), s.same)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStickyPruning(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	src := &testSource{hosts: []string{"1.1.1.1:80", "2.2.2.2:80"}}
+	p := newTestPool(t, src, PoolOptions{Size: 2, MaxAge: 5 * time.Minute, StickyDuration: 30 * time.Minute}, &now)
+	pinned, err := p.AcquireFor(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 超过 MaxAge：固定代理不再分配给其他请求，但因绑定仍保留在缓存中
+	now = now.Add(6 * time.Minute)
+	if p.Healthy() != 0 {
+		t.Fatalf("Healthy = %d, want 0 after max age", p.Healthy())
+	}
+	p.mu.Lock()
+	kept := p.indexLocked(pinned.Key()) >= 0
+	p.mu.Unlock()
+	if !kept {
+		t.Fatal("pinned proxy dropped before its binding expired")
+	}
+
+	// 绑定到期后账号绑定与代理一起被清理
+	now = now.Add(30 * time.Minute)
+	p.Status()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.sticky) != 0 || len(p.proxies) != 0 {
+		t.Fatalf("after binding expiry: %d bindings, %d proxies; want none", len(p.sticky), len(p.proxies))
+	}
+}
+
+func TestAccountContext(t *testing.T) {
+	ctx := WithAccount(context.Background(), "")
+	if got := accountFrom(ctx).get(); got != "" {
+		t.Fatalf("account = %q, want empty", got)
+	}
+	if !SetAccount(ctx, "10001") {
+		t.Fatal("SetAccount on an account context returned false")
+	}
+	if got := accountFrom(ctx).get(); got != "10001" {
+		t.Fatalf("account = %q after SetAccount", got)
+	}
+	if SetAccount(context.Background(), "10001") {
+		t.Fatal("SetAccount without WithAccount returned true")
+	}
+}
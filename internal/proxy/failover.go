@@ -0,0 +1,200 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+
+	"github.com/Yeah114/FunAuth/internal/metrics"
+)
+
+var proxySwaps = metrics.Default.NewCounterVec(
+	"funauth_proxy_swaps_total",
+	"Proxy swaps after connection failures by outcome (retried, no_proxy).",
+	"outcome")
+
+// defaultFailoverRetries 为单个请求因代理失效换代理重试的默认次数。
+const defaultFailoverRetries = 2
+
+// failoverTransport 经由代理池中的代理发送请求，并把结果反馈给代理池。
+// 当前代理出现连接类错误时封禁它、换用新的代理并重试请求：连接代理或目标失败时请求尚未发出，总是重试；
+// TLS 握手失败、超时或连接中断时只重试幂等请求。
+//
+// account 不为 nil 时使用账号的固定代理：账号确定后切换到该账号已绑定的代理（或把当前代理绑定给该账号），
+// 换代理时新的代理也会绑定给该账号。
+type failoverTransport struct {
+	pool    *Pool
+	retries int
+	account *accountRef
+
+	mu    sync.Mutex
+	proxy *Proxy
+	base  *http.Transport
+	bound string // 当前代理已绑定的账号
+}
+
+func newFailoverTransport(pool *Pool, px *Proxy, retries int, account *accountRef, bound string) *failoverTransport {
+	return &failoverTransport{pool: pool, retries: retries, account: account, proxy: px, base: newProxyTransport(px), bound: bound}
+}
+
+func newProxyTransport(px *Proxy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultIdleTimeout,
+	}
+	return &http.Transport{
+		Proxy:               http.ProxyURL(px.URL),
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: defaultDialTimeout,
+		MaxIdleConns:        32,
+		IdleConnTimeout:     defaultIdleTimeout,
+		ForceAttemptHTTP2:   true,
+	}
+}
+
+func (t *failoverTransport) current() (*Proxy, *http.Transport) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.account == nil {
+		return t.proxy, t.base
+	}
+	if uid := t.account.get(); uid != t.bound {
+		t.bound = uid
+		if uid != "" {
+			if px := t.pool.Stick(uid, t.proxy); px != t.proxy {
+				log.Printf("[proxy] switching to %s pinned for account %s", px, uid)
+				t.useLocked(px)
+			}
+		}
+	}
+	return t.proxy, t.base
+}
+
+func (t *failoverTransport) useLocked(px *Proxy) {
+	t.base.CloseIdleConnections()
+	t.proxy, t.base = px, newProxyTransport(px)
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		px, base := t.current()
+		resp, err := base.RoundTrip(req)
+		t.report(px, resp, err)
+		if err == nil || attempt >= t.retries || !canRetry(req, err) {
+			return resp, err
+		}
+
+		next, swapErr := t.swap(req.Context(), px)
+		if swapErr != nil {
+			proxySwaps.Inc("no_proxy")
+			log.Printf("[proxy] %s failed for %s %s (%v), no replacement: %v", px, req.Method, req.URL.Host, err, swapErr)
+			return nil, err
+		}
+		proxySwaps.Inc("retried")
+		log.Printf("[proxy] %s failed for %s %s (%v), retrying via %s", px, req.Method, req.URL.Host, err, next)
+
+		if req.Body != nil && req.Body != http.NoBody {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// report 把请求结果反馈给代理池。
+func (t *failoverTransport) report(px *Proxy, resp *http.Response, err error) {
+	switch {
+	case err != nil:
+		switch {
+		case errors.Is(err, context.Canceled):
+			// 调用方主动取消的请求与代理无关
+		case notSent(err):
+			// 连不上代理，或代理无法连接上游：代理本身已不可用
+			t.pool.Ban(px, BanConnection)
+		default:
+			// TLS 握手失败、超时与连接中断也可能是上游的问题，连续失败达到 MaxFailures 才封禁
+			t.pool.ReportFailure(px, BanConnection)
+		}
+	case resp.StatusCode == http.StatusProxyAuthRequired:
+		t.pool.Ban(px, BanProxyAuth)
+	case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusGatewayTimeout:
+		t.pool.ReportFailure(px, BanBlocked)
+	default:
+		t.pool.ReportSuccess(px)
+	}
+}
+
+// swap 将失效的 failed 换成代理池中的另一个代理；并发请求已经换过时直接使用新的代理。
+func (t *failoverTransport) swap(ctx context.Context, failed *Proxy) (*Proxy, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.proxy != failed {
+		return t.proxy, nil
+	}
+	next, err := t.pool.AcquireFor(ctx, t.bound)
+	if err != nil {
+		return nil, err
+	}
+	t.useLocked(next)
+	return next, nil
+}
+
+// canRetry 报告请求在 err 之后能否换代理重试。
+func canRetry(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if notSent(err) {
+		return true
+	}
+	return idempotent(req) && connectionError(err)
+}
+
+// notSent 报告 err 是否发生在请求发出之前（连接代理或经由代理连接目标失败）。
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	switch opErr.Op {
+	case "dial", "proxyconnect", "socks connect":
+		return true
+	}
+	return false
+}
+
+// connectionError 报告 err 是否为 TLS 握手失败、超时或连接中断等与代理线路有关的错误。
+func connectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	return errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &unknownAuthority) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED)
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
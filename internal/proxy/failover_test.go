@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantNotSent    bool
+		wantConnection bool
+	}{
+		{"dial proxy", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true, false},
+		{"proxy connect", &net.OpError{Op: "proxyconnect", Err: errors.New("connection refused")}, true, false},
+		{"socks connect", &net.OpError{Op: "socks connect", Err: errors.New("general failure")}, true, false},
+		{"wrapped dial", &url.Error{Op: "Post", URL: "https://x", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true, false},
+		{"reset while reading", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, false, true},
+		{"timeout", &net.OpError{Op: "read", Err: timeoutError{}}, false, true},
+		{"eof", fmt.Errorf("read response: %w", io.EOF), false, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, false, true},
+		{"other", errors.New("malformed HTTP response"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notSent(tt.err); got != tt.wantNotSent {
+				t.Errorf("notSent = %v, want %v", got, tt.wantNotSent)
+			}
+			if got := connectionError(tt.err); got != tt.wantConnection {
+				t.Errorf("connectionError = %v, want %v", got, tt.wantConnection)
+			}
+		})
+	}
+}
+
+func TestCanRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		method string
+		header string // Idempotency-Key
+		body   string
+		noGet  bool // 请求体不可重放（没有 GetBody）
+		ctx    context.Context
+		err    error
+		want   bool
+	}{
+		{name: "post not sent", method: http.MethodPost, body: "x", err: dialErr, want: true},
+		{name: "post reset", method: http.MethodPost, body: "x", err: resetErr, want: false},
+		{name: "post reset with idempotency key", method: http.MethodPost, header: "k", body: "x", err: resetErr, want: true},
+		{name: "get reset", method: http.MethodGet, err: resetErr, want: true},
+		{name: "put reset", method: http.MethodPut, body: "x", err: resetErr, want: true},
+		{name: "get other error", method: http.MethodGet, err: errors.New("bad response"), want: false},
+		{name: "body not replayable", method: http.MethodPost, body: "x", noGet: true, err: dialErr, want: false},
+		{name: "caller cancelled", method: http.MethodGet, ctx: cancelled, err: dialErr, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, "https://example.com/", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Idempotency-Key", tt.header)
+			}
+			if tt.noGet {
+				req.GetBody = nil
+			}
+			if got := canRetry(req, tt.err); got != tt.want {
+				t.Fatalf("canRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// closedAddr 返回一个当前无人监听的本地地址。
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestFailoverRoundTrip(t *testing.T) {
+	// 作为 HTTP 正向代理：收到的是目标的绝对地址请求，直接应答
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "via proxy: %s %s", r.Method, body)
+	}))
+	defer good.Close()
+	authRequired := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+	}))
+	defer authRequired.Close()
+	dead := closedAddr(t)
+	goodAddr := strings.TrimPrefix(good.URL, "http://")
+
+	tests := []struct {
+		name       string
+		hosts      []string // 按 failover 顺序
+		retries    int
+		wantStatus int
+		wantErr    bool
+		wantBanned []string
+	}{
+		{name: "dead proxy replaced", hosts: []string{dead, goodAddr}, retries: 2, wantStatus: http.StatusOK, wantBanned: []string{dead}},
+		{name: "retries disabled", hosts: []string{dead, goodAddr}, retries: 0, wantErr: true, wantBanned: []string{dead}},
+		{name: "no replacement", hosts: []string{dead}, retries: 2, wantErr: true, wantBanned: []string{dead}},
+		{name: "proxy auth required", hosts: []string{strings.TrimPrefix(authRequired.URL, "http://")}, retries: 2,
+			wantStatus: http.StatusProxyAuthRequired, wantBanned: []string{strings.TrimPrefix(authRequired.URL, "http://")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			src := &testSource{hosts: tt.hosts, rank: map[string]int{}}
+			for i, h := range tt.hosts {
+				src.rank[h] = i
+			}
+			p := newTestPool(t, src, PoolOptions{Size: len(tt.hosts), Rotation: RotateFailover}, &now)
+			first := acquire(t, p)
+			tr := newFailoverTransport(p, first, tt.retries, nil, "")
+			defer tr.base.CloseIdleConnections()
+
+			req, err := http.NewRequest(http.MethodPost, "http://upstream.invalid/login", strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := tr.RoundTrip(req)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("RoundTrip succeeded")
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+				if tt.wantStatus == http.StatusOK && string(body) != "via proxy: POST payload" {
+					t.Fatalf("body = %q, request body not replayed", body)
+				}
+			}
+			for _, h := range tt.wantBanned {
+				if !p.Banned(&Proxy{URL: &url.URL{Scheme: "http", Host: h}}) {
+					t.Errorf("%s not banned", h)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package proxy
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Mapping 描述代理池接口 JSON 响应中各字段的位置。
+//
+// 路径以 "." 分隔，数字表示数组下标，如 "data.list"、"result.0.host"；
+// 以 "|" 分隔多个候选路径时使用第一个有值的，如 "user|username"。空字段使用 DefaultMapping 中的值。
+type Mapping struct {
+	// Records 为代理记录（对象或数组）的路径，为空时自动识别：含 IP 字段的顶层对象、data 或顶层数组。
+	Records string
+	// IP 为记录中代理地址的路径；值为 "host:port" 且 Port 无值时从中取端口。
+	IP       string
+	Port     string
+	Username string
+	Password string
+	// Expiry 为过期时间的路径，支持 Unix 时间戳（秒或毫秒）、"2006-01-02 15:04:05" 与 RFC3339。
+	Expiry string
+	// Code 为响应状态码的路径，响应中没有该字段时不检查。
+	Code string
+	// SuccessCodes 为表示成功的状态码，以逗号分隔，如 "0,200" 或 "true"。
+	SuccessCodes string
+	// Message 为失败时错误信息的路径。
+	Message string
+}
+
+// DefaultMapping 返回默认的字段映射，兼容常见代理池接口。
+func DefaultMapping() Mapping {
+	return Mapping{
+		IP:           "ip",
+		Port:         "port",
+		Username:     "user|username",
+		Password:     "pwd|password",
+		Expiry:       "expire_time|expire_at|expired_at|expires_at|expire|deadline",
+		Code:         "ret",
+		SuccessCodes: "0,200",
+		Message:      "msg",
+	}
+}
+
+// WithDefaults 返回以 DefaultMapping 补全空字段后的映射。
+func (m Mapping) WithDefaults() Mapping {
+	def := DefaultMapping()
+	for _, f := range []struct{ dst, def *string }{
+		{&m.IP, &def.IP},
+		{&m.Port, &def.Port},
+		{&m.Username, &def.Username},
+		{&m.Password, &def.Password},
+		{&m.Expiry, &def.Expiry},
+		{&m.Code, &def.Code},
+		{&m.SuccessCodes, &def.SuccessCodes},
+		{&m.Message, &def.Message},
+	} {
+		if *f.dst = strings.TrimSpace(*f.dst); *f.dst == "" {
+			*f.dst = *f.def
+		}
+	}
+	m.Records = strings.TrimSpace(m.Records)
+	return m
+}
+
+// success 报告响应是否表示成功，失败时返回错误信息。
+//
+// 状态码与成功值均为默认时只检查数字状态码，与未支持字段映射前一致：非数字的 ret（如 "ok"）视为成功。
+func (m Mapping) success(root any) (bool, string) {
+	v := lookup(root, m.Code)
+	if v == nil {
+		return true, ""
+	}
+	def := DefaultMapping()
+	if _, numeric := intFromAny(v); !numeric && m.Code == def.Code && m.SuccessCodes == def.SuccessCodes {
+		return true, ""
+	}
+	code := codeFromAny(v)
+	for _, c := range strings.Split(m.SuccessCodes, ",") {
+		if strings.TrimSpace(c) == code {
+			return true, ""
+		}
+	}
+	msg := lookupString(root, m.Message)
+	if msg == "" {
+		msg = strings.TrimSpace(m.Code) + "=" + code
+	}
+	return false, msg
+}
+
+// codeFromAny 返回状态码的字符串形式；布尔值写作 "true"/"false"，以便与 SuccessCodes 比较。
+func codeFromAny(v any) string {
+	if b, ok := v.(bool); ok {
+		return strconv.FormatBool(b)
+	}
+	return stringFromAny(v)
+}
+
+// records 返回响应中的全部代理记录。
+func (m Mapping) records(root any) []*proxyRecord {
+	var candidates []any
+	switch {
+	case m.Records != "":
+		candidates = flatten(lookup(root, m.Records))
+	case lookupString(root, m.IP) != "":
+		candidates = []any{root}
+	default:
+		if obj, ok := root.(map[string]any); ok {
+			candidates = flatten(obj["data"])
+		} else {
+			candidates = flatten(root)
+		}
+	}
+
+	var out []*proxyRecord
+	for _, c := range candidates {
+		if rec := m.record(c); rec != nil {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func (m Mapping) record(v any) *proxyRecord {
+	ip := lookupString(v, m.IP)
+	if ip == "" {
+		return nil
+	}
+	rec := &proxyRecord{
+		ip:       ip,
+		port:     lookupString(v, m.Port),
+		username: lookupString(v, m.Username),
+		password: lookupString(v, m.Password),
+	}
+	if rec.port == "" {
+		if i := strings.LastIndex(ip, ":"); i > 0 && !strings.Contains(ip[:i], ":") {
+			rec.ip, rec.port = ip[:i], ip[i+1:]
+		}
+	}
+	for _, path := range alternatives(m.Expiry) {
+		if t, ok := parseExpiry(lookupPath(v, path)); ok {
+			rec.expiresAt = t
+			break
+		}
+	}
+	return rec
+}
+
+func flatten(v any) []any {
+	if list, ok := v.([]any); ok {
+		return list
+	}
+	if v == nil {
+		return nil
+	}
+	return []any{v}
+}
+
+func alternatives(path string) []string {
+	var out []string
+	for _, p := range strings.Split(path, "|") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// lookup 返回候选路径中第一个存在的值。
+func lookup(v any, path string) any {
+	for _, p := range alternatives(path) {
+		if val := lookupPath(v, p); val != nil {
+			return val
+		}
+	}
+	return nil
+}
+
+// lookupString 返回候选路径中第一个非空的字符串值。
+func lookupString(v any, path string) string {
+	for _, p := range alternatives(path) {
+		if s := stringFromAny(lookupPath(v, p)); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func lookupPath(v any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		switch cur := v.(type) {
+		case map[string]any:
+			v = cur[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(cur) {
+				return nil
+			}
+			v = cur[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
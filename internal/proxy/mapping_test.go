@@ -0,0 +1,173 @@
+package proxy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeSample(t *testing.T, body string) any {
+	t.Helper()
+	var root any
+	if err := json.Unmarshal([]byte(body), &root); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestMappingSuccess(t *testing.T) {
+	custom := Mapping{Code: "success", SuccessCodes: "true", Message: "error.detail"}.WithDefaults()
+	tests := []struct {
+		name    string
+		m       Mapping
+		body    string
+		want    bool
+		wantMsg string
+	}{
+		{"default ret 0", DefaultMapping(), `{"ret":0,"data":[]}`, true, ""},
+		{"default ret 200 string", DefaultMapping(), `{"ret":"200"}`, true, ""},
+		{"default ret error", DefaultMapping(), `{"ret":1,"msg":"余额不足"}`, false, "余额不足"},
+		{"default ret error without msg", DefaultMapping(), `{"ret":"-1"}`, false, "ret=-1"},
+		// 默认映射只检查数字状态码：非数字的 ret 视为成功
+		{"default ret non-numeric", DefaultMapping(), `{"ret":"ok"}`, true, ""},
+		{"default ret bool", DefaultMapping(), `{"ret":false}`, true, ""},
+		{"default without ret", DefaultMapping(), `{"ip":"1.1.1.1"}`, true, ""},
+		{"bool code true", custom, `{"success":true}`, true, ""},
+		{"bool code false", custom, `{"success":false,"error":{"detail":"no balance"}}`, false, "no balance"},
+		{"bool code false without message", custom, `{"success":false}`, false, "success=false"},
+		{"custom numeric codes", Mapping{Code: "code", SuccessCodes: "0, 10000"}.WithDefaults(), `{"code":10000}`, true, ""},
+		{"custom string code", Mapping{Code: "status", SuccessCodes: "ok"}.WithDefaults(), `{"status":"fail","msg":"x"}`, false, "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, msg := tt.m.success(decodeSample(t, tt.body))
+			if ok != tt.want || msg != tt.wantMsg {
+				t.Fatalf("success = %v, %q; want %v, %q", ok, msg, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestMappingRecords(t *testing.T) {
+	expiry := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	type rec struct {
+		ip, port, user, pass string
+		expires              time.Time
+	}
+	tests := []struct {
+		name string
+		m    Mapping
+		body string
+		want []rec
+	}{
+		{
+			name: "single object in data",
+			m:    DefaultMapping(),
+			body: `{"ret":0,"data":{"ip":"1.1.1.1","port":8080,"user":"u","pwd":"p","expire_time":"2024-05-01 12:00:00"}}`,
+			want: []rec{{"1.1.1.1", "8080", "u", "p", expiry}},
+		},
+		{
+			name: "list in data",
+			m:    DefaultMapping(),
+			body: `{"ret":0,"data":[{"ip":"1.1.1.1","port":"80"},{"ip":"2.2.2.2:81"},{"port":82}]}`,
+			want: []rec{{ip: "1.1.1.1", port: "80"}, {ip: "2.2.2.2", port: "81"}},
+		},
+		{
+			name: "top-level object",
+			m:    DefaultMapping(),
+			body: `{"ip":"1.1.1.1","port":80,"username":"u","password":"p","expire":` + jsonInt(expiry.Unix()) + `}`,
+			want: []rec{{"1.1.1.1", "80", "u", "p", expiry}},
+		},
+		{
+			name: "top-level array with millisecond expiry",
+			m:    DefaultMapping(),
+			body: `[{"ip":"1.1.1.1","port":80,"expires_at":` + jsonInt(expiry.UnixMilli()) + `}]`,
+			want: []rec{{ip: "1.1.1.1", port: "80", expires: expiry}},
+		},
+		{
+			name: "custom paths",
+			m:    Mapping{Records: "result.list", IP: "host", Port: "p", Username: "auth.0", Password: "auth.1", Expiry: "ttl_end"}.WithDefaults(),
+			body: `{"result":{"list":[{"host":"1.1.1.1","p":80,"auth":["u","p"],"ttl_end":"2024-05-01T12:00:00Z"}]}}`,
+			want: []rec{{"1.1.1.1", "80", "u", "p", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name: "ipv6 host kept whole",
+			m:    DefaultMapping(),
+			body: `{"data":{"ip":"::1","port":80}}`,
+			want: []rec{{ip: "::1", port: "80"}},
+		},
+		{
+			name: "no records",
+			m:    DefaultMapping(),
+			body: `{"ret":0,"data":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.records(decodeSample(t, tt.body))
+			if len(got) != len(tt.want) {
+				t.Fatalf("records returned %d, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.ip != w.ip || g.port != w.port || g.username != w.user || g.password != w.pass || !g.expiresAt.Equal(w.expires) {
+					t.Errorf("record %d = %+v, want %+v", i, *g, w)
+				}
+			}
+		})
+	}
+}
+
+func jsonInt(n int64) string {
+	raw, _ := json.Marshal(n)
+	return string(raw)
+}
+
+func TestWithDefaults(t *testing.T) {
+	m := Mapping{Records: " data.list ", IP: "host", Code: "  "}.WithDefaults()
+	def := DefaultMapping()
+	if m.Records != "data.list" || m.IP != "host" || m.Code != def.Code || m.Port != def.Port || m.SuccessCodes != def.SuccessCodes {
+		t.Fatalf("WithDefaults = %+v", m)
+	}
+}
+
+func TestParseJSONProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "multiple records",
+			body: `{"ret":0,"data":[{"ip":"1.1.1.1","port":80,"user":"u","pwd":"p"},{"ip":"2.2.2.2","port":81}]}`,
+			want: []string{"socks5://u:p@1.1.1.1:80", "socks5://2.2.2.2:81"},
+		},
+		{name: "vendor error", body: `{"ret":113,"msg":"ip not in whitelist"}`, wantErr: "proxy pool error: ip not in whitelist"},
+		{name: "empty data", body: `{"ret":0,"data":[]}`, wantErr: "missing proxy data"},
+		{name: "bad port", body: `{"ret":0,"data":{"ip":"1.1.1.1","port":"http"}}`, wantErr: "invalid proxy port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := parseProxyResponse([]byte(tt.body), "socks5", DefaultMapping())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseProxyResponse = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != len(tt.want) {
+				t.Fatalf("parsed %d proxies, want %d", len(list), len(tt.want))
+			}
+			for i, px := range list {
+				if px.URL.String() != tt.want[i] {
+					t.Errorf("proxy %d = %s, want %s", i, px.URL, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yeah114/FunAuth/internal/metrics"
+)
+
+var (
+	proxyBans = metrics.Default.NewCounterVec(
+		"funauth_proxy_bans_total",
+		"Proxies banned from the pool by reason.",
+		"reason")
+	poolHealthy = metrics.Default.NewGaugeFunc(
+		"funauth_proxy_pool_healthy",
+		"Healthy proxies currently cached in the proxy pool.",
+		nil)
+)
+
+// 封禁原因。
+const (
+	BanConnection = "connection"  // 连接代理失败，或 TLS 握手失败、超时等连续发生
+	BanProxyAuth  = "proxy_auth"  // 代理返回 407
+	BanBlocked    = "blocked"     // 上游多次拒绝经由该代理的请求（403/429 等）
+	BanHealth     = "healthcheck" // 健康检查无法连接代理
+)
+
+// Proxy 为代理池中的一个代理。
+type Proxy struct {
+	URL *url.URL
+	// ExpiresAt 为代理池接口给出的过期时间，零值表示未提供。
+	ExpiresAt time.Time
+
+	rank int // 在静态列表中的行号，failover 策略按此排序
+
+	// 以下字段由 Pool.mu 保护
+	fetchedAt   time.Time
+	lastUsed    time.Time
+	successes   int
+	failures    int
+	consecutive int
+}
+
+// Key 返回代理的标识：协议、认证信息与 host:port 均相同才视为同一代理。
+// Key 含有代理密码，不能写入日志，日志使用 String。
+func (p *Proxy) Key() string {
+	u := url.URL{Scheme: strings.ToLower(p.URL.Scheme), User: p.URL.User, Host: strings.ToLower(p.URL.Host)}
+	return u.String()
+}
+
+// String 返回不含认证信息的代理地址，用于日志。
+func (p *Proxy) String() string {
+	u := url.URL{Scheme: p.URL.Scheme, Host: p.URL.Host}
+	return u.String()
+}
+
+// 轮换策略。
+const (
+	RotateRoundRobin = "round_robin" // 使用最久未使用的代理
+	RotateRandom     = "random"      // 随机选择
+	RotateFailover   = "failover"    // 总是使用列表中排在最前的可用代理，失效后才使用下一个
+)
+
+// PoolOptions 为代理池参数，零值使用默认值。
+type PoolOptions struct {
+	// Size 为预取并保持的可用代理数量，默认 3。
+	Size int
+	// MaxAge 为接口未给出过期时间时代理的最长使用时间，默认 5m。
+	MaxAge time.Duration
+	// BanDuration 为代理被封禁的时间，默认 10m；封禁期间接口再次返回该代理也会被忽略。
+	BanDuration time.Duration
+	// MaxFailures 为连续失败多少次后封禁，默认 3。
+	MaxFailures int
+	// HealthInterval 为健康检查间隔，默认 1m。
+	HealthInterval time.Duration
+	// StickyDuration 为账号固定代理的保持时间，每次使用后重新计时，默认 30m。
+	// 可以长于 MaxAge：来源未给出过期时间的固定代理会保留到绑定到期。
+	StickyDuration time.Duration
+	// Rotation 为轮换策略，默认 RotateRoundRobin。
+	Rotation string
+}
+
+func (o *PoolOptions) setDefaults() {
+	if o.Size <= 0 {
+		o.Size = 3
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = 5 * time.Minute
+	}
+	if o.BanDuration <= 0 {
+		o.BanDuration = 10 * time.Minute
+	}
+	if o.MaxFailures <= 0 {
+		o.MaxFailures = 3
+	}
+	if o.HealthInterval <= 0 {
+		o.HealthInterval = time.Minute
+	}
+	if o.StickyDuration <= 0 {
+		o.StickyDuration = 30 * time.Minute
+	}
+}
+
+// expiryMargin 为代理到期前停止分配的提前量，避免请求进行到一半代理失效。
+const expiryMargin = 10 * time.Second
+
+// Pool 缓存从代理来源获取的代理，按使用结果跟踪其健康状态，并在后台预取与健康检查。
+type Pool struct {
+	fetch func(ctx context.Context) ([]*Proxy, error)
+	opts  PoolOptions
+
+	mu        sync.Mutex
+	proxies   []*Proxy
+	banned    map[string]time.Time
+	sticky    map[string]sticky // 以账号 uid 为键
+	refilling bool
+	lastFetch fetchResult
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	now       func() time.Time
+}
+
+// NewPool 创建代理池，fetch 每次从来源获取一个或多个代理。
+func NewPool(fetch func(ctx context.Context) ([]*Proxy, error), opts PoolOptions) *Pool {
+	opts.setDefaults()
+	p := &Pool{
+		fetch:  fetch,
+		opts:   opts,
+		banned: make(map[string]time.Time),
+		sticky: make(map[string]sticky),
+		stop:   make(chan struct{}),
+		now:    time.Now,
+	}
+	go p.maintain()
+	return p
+}
+
+// Close 停止后台预取与健康检查。
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() { close(p.stop) })
+}
+
+// Acquire 返回一个可用代理：优先使用缓存中最久未使用的健康代理，缓存为空时同步从来源获取。
+func (p *Pool) Acquire(ctx context.Context) (*Proxy, error) {
+	if px := p.pick(); px != nil {
+		return px, nil
+	}
+	if _, err := p.refill(ctx); err != nil {
+		return nil, err
+	}
+	if px := p.pick(); px != nil {
+		return px, nil
+	}
+	return nil, errors.New("proxy pool has no usable proxy")
+}
+
+func (p *Pool) pick() *Proxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.pruneLocked(now)
+	candidates := p.availableLocked(now)
+	var best *Proxy
+	switch {
+	case len(candidates) == 0:
+	case p.opts.Rotation == RotateRandom:
+		best = candidates[rand.IntN(len(candidates))]
+	case p.opts.Rotation == RotateFailover:
+		for _, px := range candidates {
+			if best == nil || px.rank < best.rank {
+				best = px
+			}
+		}
+	default:
+		for _, px := range candidates {
+			if best == nil || px.lastUsed.Before(best.lastUsed) {
+				best = px
+			}
+		}
+	}
+	if best != nil {
+		best.lastUsed = now
+	}
+	// 缓存为空时由 Acquire 同步获取，这里只在不足 Size 时后台补充
+	if best != nil && len(candidates) < p.opts.Size && !p.refilling {
+		p.refilling = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if _, err := p.refill(ctx); err != nil {
+				log.Printf("[proxy] prefetch: %v", err)
+			}
+			p.mu.Lock()
+			p.refilling = false
+			p.mu.Unlock()
+		}()
+	}
+	return best
+}
+
+// pruneLocked 移除已过期的代理、已解除的封禁记录与已到期的账号绑定。
+//
+// 来源未给出过期时间的代理超过 MaxAge 后不再分配给新的请求，但仍被账号固定时保留到绑定到期，
+// 这样 StickyDuration 长于 MaxAge 时账号也不会提前换代理；来源给出的过期时间总是生效。
+func (p *Pool) pruneLocked(now time.Time) {
+	pinned := make(map[string]bool, len(p.sticky))
+	for account, s := range p.sticky {
+		if now.After(s.until) {
+			delete(p.sticky, account)
+			continue
+		}
+		pinned[s.key] = true
+	}
+	kept := p.proxies[:0]
+	for _, px := range p.proxies {
+		if !p.expiredLocked(px, now) || (px.ExpiresAt.IsZero() && pinned[px.Key()]) {
+			kept = append(kept, px)
+		}
+	}
+	clear(p.proxies[len(kept):])
+	p.proxies = kept
+	for k, until := range p.banned {
+		if now.After(until) {
+			delete(p.banned, k)
+		}
+	}
+}
+
+// expiredLocked 报告 px 是否不能再分配给新的请求：来源给出过期时间时以其为准，否则最多使用 MaxAge。
+func (p *Pool) expiredLocked(px *Proxy, now time.Time) bool {
+	if !px.ExpiresAt.IsZero() {
+		return now.Add(expiryMargin).After(px.ExpiresAt)
+	}
+	return now.Sub(px.fetchedAt) > p.opts.MaxAge
+}
+
+// availableLocked 返回可以分配给新请求的代理，不含只为账号绑定而保留的代理。
+func (p *Pool) availableLocked(now time.Time) []*Proxy {
+	out := make([]*Proxy, 0, len(p.proxies))
+	for _, px := range p.proxies {
+		if !p.expiredLocked(px, now) {
+			out = append(out, px)
+		}
+	}
+	return out
+}
+
+// fetchResult 为最近一次从来源获取代理的结果。
+type fetchResult struct {
+	at  time.Time
+	n   int
+	err error
+}
+
+// PoolStatus 为代理池的当前状态。
+type PoolStatus struct {
+	// Healthy 为缓存中可用代理的数量。
+	Healthy int
+	// LastFetchAt 为最近一次从来源获取代理的时间，零值表示尚未获取过。
+	LastFetchAt time.Time
+	// LastFetched 为最近一次获取到的代理数量。
+	LastFetched int
+	// LastFetchErr 为最近一次获取失败的原因，成功时为 nil。
+	LastFetchErr error
+}
+
+// Status 返回代理池的当前状态，只读取缓存，不会请求来源。
+func (p *Pool) Status() PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.pruneLocked(now)
+	return PoolStatus{
+		Healthy:      len(p.availableLocked(now)),
+		LastFetchAt:  p.lastFetch.at,
+		LastFetched:  p.lastFetch.n,
+		LastFetchErr: p.lastFetch.err,
+	}
+}
+
+// refill 从来源获取代理并加入缓存，返回新加入的数量；被封禁或已在缓存中的代理会被忽略。
+func (p *Pool) refill(ctx context.Context) (int, error) {
+	fetched, err := p.fetch(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.lastFetch = fetchResult{at: now, n: len(fetched), err: err}
+	if err != nil {
+		return 0, err
+	}
+	added, cached := 0, 0
+	for _, px := range fetched {
+		if p.indexLocked(px.Key()) >= 0 {
+			cached++
+			continue
+		}
+		if _, banned := p.banned[px.Key()]; banned {
+			continue
+		}
+		px.fetchedAt = now
+		if p.expiredLocked(px, now) {
+			continue
+		}
+		p.proxies = append(p.proxies, px)
+		added++
+	}
+	if added == 0 && cached == 0 && len(fetched) > 0 {
+		return 0, errors.New("proxy source returned only banned or expired proxies")
+	}
+	return added, nil
+}
+
+func (p *Pool) indexLocked(key string) int {
+	for i, px := range p.proxies {
+		if px.Key() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReportSuccess 记录一次经由 px 的成功请求。
+func (p *Pool) ReportSuccess(px *Proxy) {
+	p.mu.Lock()
+	px.successes++
+	px.consecutive = 0
+	p.mu.Unlock()
+}
+
+// ReportFailure 记录一次经由 px 的失败请求，连续失败达到 MaxFailures 时以 reason 封禁。
+func (p *Pool) ReportFailure(px *Proxy, reason string) {
+	p.mu.Lock()
+	px.failures++
+	px.consecutive++
+	ban := px.consecutive >= p.opts.MaxFailures
+	p.mu.Unlock()
+	if ban {
+		p.Ban(px, reason)
+	}
+}
+
+// Ban 将 px 移出缓存并在 BanDuration 内忽略来源再次返回的同一代理。
+func (p *Pool) Ban(px *Proxy, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, already := p.banned[px.Key()]; already {
+		return
+	}
+	p.banned[px.Key()] = p.now().Add(p.opts.BanDuration)
+	if i := p.indexLocked(px.Key()); i >= 0 {
+		p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+	}
+	proxyBans.Inc(reason)
+	log.Printf("[proxy] banned %s for %s (%s, %d ok / %d failed)", px, p.opts.BanDuration, reason, px.successes, px.failures)
+}
+
+// Banned 报告 px 当前是否被封禁。
+func (p *Pool) Banned(px *Proxy) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	until, ok := p.banned[px.Key()]
+	return ok && p.now().Before(until)
+}
+
+// Healthy 返回缓存中可分配给新请求的代理数量。
+func (p *Pool) Healthy() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.pruneLocked(now)
+	return len(p.availableLocked(now))
+}
+
+// maintain 定期对缓存中的代理做连通性检查，并保持缓存数量。
+func (p *Pool) maintain() {
+	ticker := time.NewTicker(p.opts.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		p.healthCheck()
+		if p.Healthy() < p.opts.Size {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if _, err := p.refill(ctx); err != nil {
+				log.Printf("[proxy] prefetch: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (p *Pool) healthCheck() {
+	p.mu.Lock()
+	p.pruneLocked(p.now())
+	list := append([]*Proxy(nil), p.proxies...)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, px := range list {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := net.DialTimeout("tcp", px.URL.Host, defaultDialTimeout)
+			if err != nil {
+				p.Ban(px, BanHealth)
+				return
+			}
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testSource 模拟代理来源，每次获取都返回 hosts 对应的新代理。
+type testSource struct {
+	hosts   []string
+	expires map[string]time.Time
+	rank    map[string]int
+	calls   int
+}
+
+func (s *testSource) fetch(context.Context) ([]*Proxy, error) {
+	s.calls++
+	var list []*Proxy
+	for _, h := range s.hosts {
+		list = append(list, &Proxy{URL: &url.URL{Scheme: "http", Host: h}, ExpiresAt: s.expires[h], rank: s.rank[h]})
+	}
+	return list, nil
+}
+
+// newTestPool 创建使用 now 作为时钟的代理池；关闭后台预取，代理只在 Acquire 缓存为空时同步获取。
+func newTestPool(t *testing.T, src *testSource, opts PoolOptions, now *time.Time) *Pool {
+	t.Helper()
+	p := NewPool(src.fetch, opts)
+	p.now = func() time.Time { return *now }
+	p.refilling = true
+	t.Cleanup(p.Close)
+	return p
+}
+
+func acquire(t *testing.T, p *Pool) *Proxy {
+	t.Helper()
+	px, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return px
+}
+
+func TestPoolFailuresBan(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      []string // "fail"、"ok" 或 "ban"
+		wantBanned bool
+	}{
+		{"below max failures", []string{"fail", "fail"}, false},
+		{"consecutive failures", []string{"fail", "fail", "fail"}, true},
+		{"success resets", []string{"fail", "fail", "ok", "fail", "fail"}, false},
+		{"explicit ban", []string{"ban"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			p := newTestPool(t, &testSource{hosts: []string{"1.1.1.1:80"}}, PoolOptions{MaxFailures: 3}, &now)
+			px := acquire(t, p)
+			for _, s := range tt.steps {
+				switch s {
+				case "fail":
+					p.ReportFailure(px, BanBlocked)
+				case "ok":
+					p.ReportSuccess(px)
+				case "ban":
+					p.Ban(px, BanProxyAuth)
+				}
+			}
+			if got := p.Banned(px); got != tt.wantBanned {
+				t.Fatalf("Banned = %v, want %v", got, tt.wantBanned)
+			}
+			wantHealthy := 1
+			if tt.wantBanned {
+				wantHealthy = 0
+			}
+			if p.Healthy() != wantHealthy {
+				t.Fatalf("Healthy = %d, want %d", p.Healthy(), wantHealthy)
+			}
+		})
+	}
+}
+
+func TestPoolBanExpires(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	src := &testSource{hosts: []string{"1.1.1.1:80"}}
+	p := newTestPool(t, src, PoolOptions{BanDuration: 10 * time.Minute}, &now)
+	p.Ban(acquire(t, p), BanConnection)
+
+	// 封禁期间来源再次返回同一代理也会被忽略
+	now = now.Add(9 * time.Minute)
+	if _, err := p.Acquire(context.Background()); err == nil {
+		t.Fatal("Acquire returned a banned proxy")
+	}
+	now = now.Add(2 * time.Minute)
+	if px := acquire(t, p); px.Key() != "http://1.1.1.1:80" || p.Banned(px) {
+		t.Fatalf("Acquire after ban expiry = %s, banned %v", px.Key(), p.Banned(px))
+	}
+}
+
+func TestPoolExpiry(t *testing.T) {
+	t0 := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name    string
+		expires time.Duration // 来源给出的剩余有效期，0 表示未给出
+		age     time.Duration
+		want    bool
+	}{
+		{"within max age", 0, 4 * time.Minute, true},
+		{"past max age", 0, 6 * time.Minute, false},
+		{"source expiry beats max age", time.Hour, 6 * time.Minute, true},
+		{"before expiry margin", time.Minute, 45 * time.Second, true},
+		{"inside expiry margin", time.Minute, 55 * time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := t0
+			src := &testSource{hosts: []string{"1.1.1.1:80"}}
+			if tt.expires > 0 {
+				src.expires = map[string]time.Time{"1.1.1.1:80": t0.Add(tt.expires)}
+			}
+			p := newTestPool(t, src, PoolOptions{MaxAge: 5 * time.Minute}, &now)
+			acquire(t, p)
+			now = now.Add(tt.age)
+			if got := p.Healthy() == 1; got != tt.want {
+				t.Fatalf("usable after %s = %v, want %v", tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolRotation(t *testing.T) {
+	hosts := []string{"1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80"}
+	tests := []struct {
+		rotation string
+		want     []string
+	}{
+		{RotateRoundRobin, []string{"1.1.1.1:80", "2.2.2.2:80", "3.3.3.3:80", "1.1.1.1:80"}},
+		{RotateFailover, []string{"2.2.2.2:80", "2.2.2.2:80", "2.2.2.2:80", "2.2.2.2:80"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rotation, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			src := &testSource{hosts: hosts, rank: map[string]int{"1.1.1.1:80": 3, "2.2.2.2:80": 1, "3.3.3.3:80": 2}}
+			p := newTestPool(t, src, PoolOptions{Size: len(hosts), Rotation: tt.rotation}, &now)
+			for i, want := range tt.want {
+				now = now.Add(time.Second)
+				if px := acquire(t, p); px.URL.Host != want {
+					t.Fatalf("pick %d = %s, want %s", i, px.URL.Host, want)
+				}
+			}
+			if src.calls != 1 {
+				t.Fatalf("source fetched %d times, want 1", src.calls)
+			}
+		})
+	}
+}
+
+func TestPoolStatus(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newTestPool(t, &testSource{hosts: []string{"1.1.1.1:80", "2.2.2.2:80"}}, PoolOptions{}, &now)
+	if st := p.Status(); !st.LastFetchAt.IsZero() || st.Healthy != 0 {
+		t.Fatalf("Status before first fetch = %+v", st)
+	}
+	acquire(t, p)
+	st := p.Status()
+	if !st.LastFetchAt.Equal(now) || st.LastFetched != 2 || st.Healthy != 2 || st.LastFetchErr != nil {
+		t.Fatalf("Status = %+v", st)
+	}
+}
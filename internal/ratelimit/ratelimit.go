@@ -0,0 +1,215 @@
+// Package ratelimit 提供按键区分的令牌桶限流器。
+//
+// 每个键对应一个令牌桶：以 Rate 的速率补充令牌，最多积累 Burst 个。
+// Allow 在无令牌时立即拒绝并给出建议的重试时间；Wait 则预约一个令牌并排队等待。
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idleTTL 为令牌桶在无访问后被回收的最短时间；桶还要空闲到足以补满才会被回收（此时与新建等价）。
+const idleTTL = 10 * time.Minute
+
+// ErrWaitTooLong 表示排队等待时间超过上限。
+var ErrWaitTooLong = errors.New("rate limit wait exceeds limit")
+
+// Spec 为令牌桶参数；Rate<=0 表示不限流。
+type Spec struct {
+	// Rate 为每秒补充的令牌数。
+	Rate float64
+	// Burst 为桶容量，<=0 时为 1。
+	Burst int
+}
+
+// Enabled 报告是否启用限流。
+func (s Spec) Enabled() bool { return s.Rate > 0 }
+
+// String 返回 ParseSpec 可解析的形式。
+func (s Spec) String() string {
+	if !s.Enabled() {
+		return "off"
+	}
+	return strconv.FormatFloat(s.Rate, 'f', -1, 64) + "/s:" + strconv.Itoa(s.Burst)
+}
+
+// ParseSpec 解析 "<n>/<s|m|h>[:burst]" 形式的限流参数，例如 "10/s:20"、"6/m"；
+// "off" 或 "0" 表示不限流，省略 burst 时为 1。
+func ParseSpec(v string) (Spec, error) {
+	v = strings.TrimSpace(strings.ToLower(v))
+	if v == "off" || v == "0" || v == "" {
+		return Spec{}, nil
+	}
+	rate, burstStr, hasBurst := strings.Cut(v, ":")
+	nStr, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Spec{}, fmt.Errorf("ratelimit: bad spec %q, want n/unit[:burst]", v)
+	}
+	n, err := strconv.ParseFloat(nStr, 64)
+	if err != nil || n < 0 {
+		return Spec{}, fmt.Errorf("ratelimit: bad rate in %q", v)
+	}
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Spec{}, fmt.Errorf("ratelimit: bad unit in %q", v)
+	}
+	spec := Spec{Rate: n / per.Seconds(), Burst: 1}
+	if hasBurst {
+		if spec.Burst, err = strconv.Atoi(burstStr); err != nil || spec.Burst <= 0 {
+			return Spec{}, fmt.Errorf("ratelimit: bad burst in %q", v)
+		}
+	}
+	return spec, nil
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter 为按键区分的令牌桶限流器，并发安全。
+type Limiter struct {
+	spec Spec
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// New 创建限流器。
+func New(spec Spec) *Limiter {
+	if spec.Burst <= 0 {
+		spec.Burst = 1
+	}
+	return &Limiter{spec: spec, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Spec 返回限流参数。
+func (l *Limiter) Spec() Spec {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.spec
+}
+
+// SetSpec 在运行时替换限流参数；已有令牌桶保留当前令牌数（不超过新的容量）。
+func (l *Limiter) SetSpec(spec Spec) {
+	if spec.Burst <= 0 {
+		spec.Burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.spec = spec
+	for _, b := range l.buckets {
+		b.tokens = math.Min(b.tokens, float64(spec.Burst))
+	}
+}
+
+// Allow 尝试立即取得一个令牌；失败时返回距下一个令牌可用的时间。
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.spec.Enabled() {
+		return true, 0
+	}
+	b := l.refillLocked(key)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, l.deficit(1 - b.tokens)
+}
+
+// Wait 预约一个令牌并等待其可用；需要等待超过 maxWait（>0 时）则不预约并返回 ErrWaitTooLong
+// 以及建议的重试时间。ctx 取消时预约的令牌会被归还。
+func (l *Limiter) Wait(ctx context.Context, key string, maxWait time.Duration) (time.Duration, error) {
+	l.mu.Lock()
+	if !l.spec.Enabled() {
+		l.mu.Unlock()
+		return 0, nil
+	}
+	b := l.refillLocked(key)
+	wait := time.Duration(0)
+	if b.tokens < 1 {
+		wait = l.deficit(1 - b.tokens)
+	}
+	if maxWait > 0 && wait > maxWait {
+		l.mu.Unlock()
+		return wait, ErrWaitTooLong
+	}
+	// 令牌数可以为负，表示已被排队中的调用预约
+	b.tokens--
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return wait, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if b, ok := l.buckets[key]; ok {
+			b.tokens = math.Min(b.tokens+1, float64(l.spec.Burst))
+		}
+		l.mu.Unlock()
+		return wait, ctx.Err()
+	}
+}
+
+// Len 返回当前跟踪的键数量。
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+func (l *Limiter) refillLocked(key string) *bucket {
+	now := l.now()
+	if now.Sub(l.lastSweep) > idleTTL {
+		for k, b := range l.buckets {
+			if idle := now.Sub(b.last); idle > idleTTL && idle >= l.refillTime(b.tokens) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.spec.Burst), last: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*l.spec.Rate, float64(l.spec.Burst))
+	b.last = now
+	return b
+}
+
+// refillTime 返回令牌数从 tokens 补满到 Burst 所需的时间；不限流时为 0。
+func (l *Limiter) refillTime(tokens float64) time.Duration {
+	if !l.spec.Enabled() {
+		return 0
+	}
+	return l.deficit(float64(l.spec.Burst) - tokens)
+}
+
+func (l *Limiter) deficit(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.spec.Rate * float64(time.Second)))
+}
@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(spec Spec, now *time.Time) *Limiter {
+	l := New(spec)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Spec
+		wantErr bool
+	}{
+		{"10/s:20", Spec{Rate: 10, Burst: 20}, false},
+		{"6/m", Spec{Rate: 0.1, Burst: 1}, false},
+		{" 36/H:3 ", Spec{Rate: 0.01, Burst: 3}, false},
+		{"off", Spec{}, false},
+		{"0", Spec{}, false},
+		{"", Spec{}, false},
+		{"10", Spec{}, true},
+		{"10/d", Spec{}, true},
+		{"x/s", Spec{}, true},
+		{"-1/s", Spec{}, true},
+		{"10/s:0", Spec{}, true},
+		{"10/s:x", Spec{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSpec(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpec err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseSpec = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowRefill(t *testing.T) {
+	type step struct {
+		after     time.Duration // 距上一步的时间
+		want      bool
+		wantRetry time.Duration
+	}
+	tests := []struct {
+		name  string
+		spec  Spec
+		steps []step
+	}{
+		{
+			name: "burst then deny",
+			spec: Spec{Rate: 1, Burst: 2},
+			steps: []step{
+				{0, true, 0},
+				{0, true, 0},
+				{0, false, time.Second},
+			},
+		},
+		{
+			name: "partial refill",
+			spec: Spec{Rate: 1, Burst: 1},
+			steps: []step{
+				{0, true, 0},
+				{400 * time.Millisecond, false, 600 * time.Millisecond},
+				{600 * time.Millisecond, true, 0},
+			},
+		},
+		{
+			name: "refill capped at burst",
+			spec: Spec{Rate: 10, Burst: 2},
+			steps: []step{
+				{0, true, 0},
+				{0, true, 0},
+				{time.Hour, true, 0},
+				{0, true, 0},
+				{0, false, 100 * time.Millisecond},
+			},
+		},
+		{
+			name: "disabled",
+			spec: Spec{},
+			steps: []step{
+				{0, true, 0},
+				{0, true, 0},
+				{0, true, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			l := newTestLimiter(tt.spec, &now)
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				ok, retry := l.Allow("k")
+				if ok != s.want || retry != s.wantRetry {
+					t.Fatalf("step %d: Allow = %v, %s; want %v, %s", i, ok, retry, s.want, s.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestAllowKeysIndependent(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(Spec{Rate: 1, Burst: 1}, &now)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("first request for a denied")
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatal("b throttled by a")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("second request for a allowed")
+	}
+}
+
+func TestSetSpecClampsTokens(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(Spec{Rate: 1, Burst: 5}, &now)
+	l.Allow("k")
+	l.SetSpec(Spec{Rate: 1, Burst: 1})
+	if ok, _ := l.Allow("k"); !ok {
+		t.Fatal("token lost when shrinking burst")
+	}
+	if ok, _ := l.Allow("k"); ok {
+		t.Fatal("tokens above the new burst kept")
+	}
+}
+
+func TestWait(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(Spec{Rate: 1, Burst: 1}, &now)
+	ctx := context.Background()
+
+	if wait, err := l.Wait(ctx, "k", time.Second); err != nil || wait != 0 {
+		t.Fatalf("Wait with a free token = %s, %v", wait, err)
+	}
+	// 令牌已用尽，下一个需要 1s，超过 maxWait 时不预约
+	if wait, err := l.Wait(ctx, "k", 500*time.Millisecond); !errors.Is(err, ErrWaitTooLong) || wait != time.Second {
+		t.Fatalf("Wait over max = %s, %v; want 1s, ErrWaitTooLong", wait, err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.Wait(cancelled, "k", 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait with cancelled ctx = %v", err)
+	}
+	// 被取消的预约已归还，1s 后仍有令牌可用
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("k"); !ok {
+		t.Fatal("cancelled reservation not returned")
+	}
+}
+
+func TestIdleBucketsSwept(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newTestLimiter(Spec{Rate: 1, Burst: 1}, &now)
+	l.Allow("a")
+	now = now.Add(idleTTL + time.Second)
+	l.Allow("b")
+	if l.Len() != 1 {
+		t.Fatalf("Len = %d after idle sweep, want 1", l.Len())
+	}
+}
@@ -0,0 +1,279 @@
+package session
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	opPut    = "put"
+	opDelete = "del"
+
+	compactInterval = 10 * time.Minute
+	// compactMinRecords 为触发压缩的最少日志记录数，避免小文件频繁重写。
+	compactMinRecords = 1024
+)
+
+// record 为追加日志中的一行。
+type record struct {
+	Op         string    `json:"op"`
+	BearerHash string    `json:"bearer_sha256,omitempty"`
+	Data       *Data     `json:"data,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+
+	// Bearer 为旧版本日志中的明文 Bearer，只在重放时读取，随后的压缩会改写为 BearerHash。
+	Bearer string `json:"bearer,omitempty"`
+}
+
+// hashBearer 返回 bearer 的 SHA-256（十六进制），FileStore 只以它作为会话的键。
+func hashBearer(bearer string) string {
+	sum := sha256.Sum256([]byte(bearer))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileStore 在 MemoryStore 之上把每次写入追加到本地日志文件，
+// 进程重启后通过重放日志恢复会话；日志会在启动时及后台定期压缩。
+//
+// 日志与内存中都只保存 Bearer 的 SHA-256，日志文件泄露不会暴露可用的 Bearer。
+// 读取只访问内存，恢复出的会话的空闲计时从重启时刻重新开始。
+type FileStore struct {
+	mem  *MemoryStore
+	path string
+
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	records int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// OpenFileStore 打开（不存在时创建）cfg.Path 指向的会话日志。
+func OpenFileStore(cfg Config) (*FileStore, error) {
+	cfg = cfg.withDefaults()
+	s := &FileStore{
+		mem:  NewMemoryStore(cfg),
+		path: cfg.Path,
+		stop: make(chan struct{}),
+	}
+	if err := s.replay(); err != nil {
+		s.mem.Close()
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		s.mem.Close()
+		return nil, err
+	}
+	go s.compactor()
+	return s, nil
+}
+
+// Get 实现 Store。
+func (s *FileStore) Get(bearer string) (Data, bool) {
+	if bearer == "" {
+		return Data{}, false
+	}
+	return s.mem.Get(hashBearer(bearer))
+}
+
+// Put 实现 Store。
+func (s *FileStore) Put(bearer string, data Data) {
+	if bearer == "" {
+		return
+	}
+	key := hashBearer(bearer)
+	s.mem.Put(key, data)
+	s.append(record{Op: opPut, BearerHash: key, Data: &data, CreatedAt: time.Now()})
+}
+
+// Delete 实现 Store。
+func (s *FileStore) Delete(bearer string) {
+	key := hashBearer(bearer)
+	s.mem.Delete(key)
+	s.append(record{Op: opDelete, BearerHash: key})
+}
+
+// Len 实现 Store。
+func (s *FileStore) Len() int {
+	return s.mem.Len()
+}
+
+// Close 压缩并关闭日志文件。
+func (s *FileStore) Close() error {
+	var err error
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		err = s.compact()
+		s.mu.Lock()
+		if s.f != nil {
+			if cerr := s.f.Close(); err == nil {
+				err = cerr
+			}
+			s.f, s.w = nil, nil
+		}
+		s.mu.Unlock()
+		s.mem.Close()
+	})
+	return err
+}
+
+func (s *FileStore) append(rec record) {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("[session] encode record: %v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w == nil {
+		return
+	}
+	s.w.Write(raw)
+	s.w.WriteByte('\n')
+	if err := s.w.Flush(); err != nil {
+		log.Printf("[session] append %s: %v", s.path, err)
+		return
+	}
+	s.records++
+}
+
+// replay 按顺序重放日志，跳过无法解析的行（例如崩溃时写了一半的最后一行）。
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	line, bad := 0, 0
+	for sc.Scan() {
+		line++
+		var rec record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			bad++
+			continue
+		}
+		key := rec.BearerHash
+		if key == "" && rec.Bearer != "" {
+			key = hashBearer(rec.Bearer)
+		}
+		if key == "" {
+			bad++
+			continue
+		}
+		switch rec.Op {
+		case opPut:
+			if rec.Data != nil {
+				s.mem.restore(key, *rec.Data, rec.CreatedAt)
+			}
+		case opDelete:
+			s.mem.Delete(key)
+		default:
+			bad++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", s.path, err)
+	}
+	if bad > 0 {
+		log.Printf("[session] %s: skipped %d of %d malformed records", s.path, bad, line)
+	}
+	return nil
+}
+
+// compact 将当前有效会话写入新文件并原子替换日志。
+//
+// 快照在持有 s.mu 时获取：之后的写入都会等到压缩完成再追加到新文件，不会写进即将被替换的旧文件而丢失。
+func (s *FileStore) compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.mem.snapshot()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for i := range entries {
+		e := &entries[i]
+		if err := enc.Encode(record{Op: opPut, BearerHash: e.bearer, Data: &e.data, CreatedAt: e.createdAt}); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if s.f != nil {
+		s.f.Close()
+		s.f, s.w = nil, nil
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	select {
+	case <-s.stop:
+		// 关闭过程中不再重新打开日志
+		return nil
+	default:
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	s.f, s.w = f, bufio.NewWriter(f)
+	s.records = len(entries)
+	return nil
+}
+
+func (s *FileStore) compactor() {
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			records := s.records
+			s.mu.Unlock()
+			if records < compactMinRecords || records < 2*s.mem.Len() {
+				continue
+			}
+			if err := s.compact(); err != nil {
+				log.Printf("[session] compact %s: %v", s.path, err)
+			}
+		}
+	}
+}
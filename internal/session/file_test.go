@@ -0,0 +1,133 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestFileStore(t *testing.T, path string) *FileStore {
+	t.Helper()
+	s, err := OpenFileStore(Config{Backend: BackendFile, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFileStoreKeepsOnlyBearerHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.log")
+	s := openTestFileStore(t, path)
+	s.Put("secret-bearer", Data{UserID: "1"})
+	s.Put("other-bearer", Data{UserID: "2"})
+	s.Delete("other-bearer")
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret-bearer") || strings.Contains(string(raw), "other-bearer") {
+		t.Fatalf("log contains a raw bearer:\n%s", raw)
+	}
+	if !strings.Contains(string(raw), hashBearer("secret-bearer")) {
+		t.Fatalf("log lacks the bearer hash:\n%s", raw)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openTestFileStore(t, path)
+	defer s.Close()
+	if d, ok := s.Get("secret-bearer"); !ok || d.UserID != "1" {
+		t.Fatalf("Get after replay = %+v, %v", d, ok)
+	}
+	if _, ok := s.Get("other-bearer"); ok {
+		t.Fatal("deleted session restored")
+	}
+	if _, ok := s.Get(hashBearer("secret-bearer")); ok {
+		t.Fatal("session found by its hash instead of the bearer")
+	}
+}
+
+func TestFileStoreRewritesLegacyBearer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.log")
+	legacy, err := json.Marshal(record{Op: opPut, Bearer: "legacy-bearer", Data: &Data{UserID: "7"}, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(legacy, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openTestFileStore(t, path)
+	defer s.Close()
+	if d, ok := s.Get("legacy-bearer"); !ok || d.UserID != "7" {
+		t.Fatalf("Get legacy session = %+v, %v", d, ok)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "legacy-bearer") {
+		t.Fatalf("raw bearer survived compaction:\n%s", raw)
+	}
+}
+
+func TestFileStoreCompactsOnReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.log")
+	now := time.Now()
+	put := func(bearer, uid string, created time.Time) record {
+		return record{Op: opPut, BearerHash: hashBearer(bearer), Data: &Data{UserID: uid}, CreatedAt: created}
+	}
+	recs := []record{
+		put("kept", "1", now),
+		put("overwritten", "2", now),
+		put("overwritten", "3", now),
+		put("deleted", "4", now),
+		{Op: opDelete, BearerHash: hashBearer("deleted")},
+		put("expired", "5", now.Add(-48*time.Hour)),
+	}
+	var buf []byte
+	for _, rec := range recs {
+		raw, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf = append(append(buf, raw...), '\n')
+	}
+	buf = append(buf, `{"op":"put","bearer_sha256":`...) // 崩溃时写了一半的行
+	if err := os.WriteFile(path, buf, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := openTestFileStore(t, path)
+	defer s.Close()
+	want := map[string]string{"kept": "1", "overwritten": "3"}
+	for _, bearer := range []string{"kept", "overwritten", "deleted", "expired"} {
+		d, ok := s.Get(bearer)
+		if uid, live := want[bearer]; ok != live || d.UserID != uid {
+			t.Errorf("Get(%q) = %+v, %v", bearer, d, ok)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("compacted log has %d records, want %d:\n%s", len(lines), len(want), raw)
+	}
+	for _, line := range lines {
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("bad compacted record %q: %v", line, err)
+		}
+		if rec.Op != opPut || rec.Data == nil {
+			t.Fatalf("compacted record = %+v", rec)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package session 保存 /phoenix/login 成功后与 Bearer 关联的会话信息，
+// 供 transfer_check_num、transfer_start_type 等后续请求读取。
+package session
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 会话存储后端。
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+const (
+	defaultFilePath        = "funauth-sessions.log"
+	defaultTTL             = 24 * time.Hour
+	defaultIdleTimeout     = 2 * time.Hour
+	defaultMaxEntries      = 10000
+	defaultJanitorInterval = time.Minute
+)
+
+// Data 为一个会话保存的内容。
+type Data struct {
+	EntityID      string `json:"entity_id"`
+	EngineVersion string `json:"engine_version"`
+	PatchVersion  string `json:"patch_version"`
+	UserID        string `json:"user_id"`
+	IsPC          bool   `json:"is_pc"`
+}
+
+// Config 为会话存储配置，零值字段使用默认值。
+type Config struct {
+	// TTL 为会话自创建起的最长存活时间，默认 24h。
+	TTL time.Duration
+	// IdleTimeout 为会话在无访问情况下的最长保留时间，默认 2h。
+	IdleTimeout time.Duration
+	// MaxEntries 为最多保存的会话数，超出时淘汰最久未访问的会话，默认 10000。
+	MaxEntries int
+	// JanitorInterval 为后台清理过期会话的间隔。
+	JanitorInterval time.Duration
+	// Backend 为存储后端：memory（默认）或 file。
+	Backend string
+	// Path 为 file 后端的日志文件路径，默认 funauth-sessions.log。
+	Path string
+}
+
+func (c Config) withDefaults() Config {
+	if c.TTL <= 0 {
+		c.TTL = defaultTTL
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = defaultIdleTimeout
+	}
+	if c.MaxEntries <= 0 {
+		c.MaxEntries = defaultMaxEntries
+	}
+	if c.JanitorInterval <= 0 {
+		c.JanitorInterval = defaultJanitorInterval
+	}
+	if c.Backend == "" {
+		c.Backend = BackendMemory
+	}
+	if c.Path == "" {
+		c.Path = defaultFilePath
+	}
+	return c
+}
+
+// Open 按 cfg.Backend 创建会话存储。
+func Open(cfg Config) (Store, error) {
+	cfg = cfg.withDefaults()
+	switch cfg.Backend {
+	case BackendMemory:
+		return NewMemoryStore(cfg), nil
+	case BackendFile:
+		return OpenFileStore(cfg)
+	default:
+		return nil, fmt.Errorf("unknown session backend %q", cfg.Backend)
+	}
+}
+
+type entry struct {
+	// bearer 为会话的键：MemoryStore 中为 Bearer 本身，FileStore 中为其 SHA-256。
+	bearer    string
+	data      Data
+	createdAt time.Time
+	lastSeen  time.Time
+}
+
+// Store 为会话存储。
+type Store interface {
+	// Get 返回 bearer 对应的会话并刷新其访问时间；会话不存在或已过期时返回 false。
+	Get(bearer string) (Data, bool)
+	// Put 创建或覆盖 bearer 对应的会话。
+	Put(bearer string, data Data)
+	// Delete 删除 bearer 对应的会话。
+	Delete(bearer string)
+	// Len 返回当前保存的会话数。
+	Len() int
+	// Close 停止后台任务并释放资源。
+	Close() error
+}
+
+// MemoryStore 为内存中的会话存储，带有过期、空闲超时与容量上限。
+type MemoryStore struct {
+	cfg Config
+
+	mu    sync.Mutex
+	ll    *list.List // 按最近访问排序，Front 为最新
+	items map[string]*list.Element
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMemoryStore 创建内存会话存储并启动后台清理协程。
+func NewMemoryStore(cfg Config) *MemoryStore {
+	s := &MemoryStore{
+		cfg:   cfg.withDefaults(),
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		stop:  make(chan struct{}),
+	}
+	go s.janitor()
+	return s
+}
+
+// Get 实现 Store。
+func (s *MemoryStore) Get(bearer string) (Data, bool) {
+	if bearer == "" {
+		return Data{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[bearer]
+	if !ok {
+		return Data{}, false
+	}
+	e := el.Value.(*entry)
+	now := time.Now()
+	if s.expired(e, now) {
+		s.removeElement(el)
+		return Data{}, false
+	}
+	e.lastSeen = now
+	s.ll.MoveToFront(el)
+	return e.data, true
+}
+
+// Put 实现 Store。
+func (s *MemoryStore) Put(bearer string, data Data) {
+	if bearer == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.put(bearer, data, now, now)
+}
+
+func (s *MemoryStore) put(bearer string, data Data, createdAt, lastSeen time.Time) {
+	if el, ok := s.items[bearer]; ok {
+		s.removeElement(el)
+	}
+	s.items[bearer] = s.ll.PushFront(&entry{bearer: bearer, data: data, createdAt: createdAt, lastSeen: lastSeen})
+	for s.ll.Len() > s.cfg.MaxEntries {
+		s.removeElement(s.ll.Back())
+	}
+}
+
+// restore 写入从持久化存储恢复的会话，已过期的会话会被忽略。
+func (s *MemoryStore) restore(bearer string, data Data, createdAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(createdAt) > s.cfg.TTL {
+		return
+	}
+	s.put(bearer, data, createdAt, now)
+}
+
+// snapshot 按最久未访问在前的顺序返回全部未过期会话。
+func (s *MemoryStore) snapshot() []entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	out := make([]entry, 0, s.ll.Len())
+	for el := s.ll.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*entry)
+		if !s.expired(e, now) {
+			out = append(out, *e)
+		}
+	}
+	return out
+}
+
+// Delete 实现 Store。
+func (s *MemoryStore) Delete(bearer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[bearer]; ok {
+		s.removeElement(el)
+	}
+}
+
+// Len 返回当前保存的会话数（可能包含尚未被清理的过期会话）。
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// Close 停止后台清理协程。
+func (s *MemoryStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	return nil
+}
+
+// Prune 立即清理所有过期会话，返回清理数量。
+func (s *MemoryStore) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	removed := 0
+	for el := s.ll.Back(); el != nil; {
+		prev := el.Prev()
+		if s.expired(el.Value.(*entry), now) {
+			s.removeElement(el)
+			removed++
+		}
+		el = prev
+	}
+	return removed
+}
+
+func (s *MemoryStore) janitor() {
+	ticker := time.NewTicker(s.cfg.JanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Prune()
+		}
+	}
+}
+
+func (s *MemoryStore) expired(e *entry, now time.Time) bool {
+	return now.Sub(e.createdAt) > s.cfg.TTL || now.Sub(e.lastSeen) > s.cfg.IdleTimeout
+}
+
+func (s *MemoryStore) removeElement(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*entry).bearer)
+}
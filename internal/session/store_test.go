@@ -0,0 +1,126 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	cfg := Config{TTL: time.Hour, IdleTimeout: 10 * time.Minute}
+	tests := []struct {
+		name     string
+		created  time.Duration // 距今多久前创建
+		lastSeen time.Duration // 距今多久前访问
+		want     bool
+	}{
+		{"fresh", 0, 0, true},
+		{"recently used", 50 * time.Minute, time.Minute, true},
+		{"ttl exceeded", 61 * time.Minute, time.Minute, false},
+		{"idle exceeded", 20 * time.Minute, 11 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore(cfg)
+			defer s.Close()
+			now := time.Now()
+			s.mu.Lock()
+			s.put("b", Data{UserID: "1"}, now.Add(-tt.created), now.Add(-tt.lastSeen))
+			s.mu.Unlock()
+
+			if _, ok := s.Get("b"); ok != tt.want {
+				t.Fatalf("Get ok = %v, want %v", ok, tt.want)
+			}
+			if !tt.want && s.Len() != 0 {
+				t.Fatalf("expired session kept, Len = %d", s.Len())
+			}
+		})
+	}
+}
+
+func TestMemoryStoreGetRefreshesIdle(t *testing.T) {
+	s := NewMemoryStore(Config{IdleTimeout: 10 * time.Minute})
+	defer s.Close()
+	now := time.Now()
+	s.mu.Lock()
+	s.put("b", Data{}, now.Add(-15*time.Minute), now.Add(-9*time.Minute))
+	s.mu.Unlock()
+	if _, ok := s.Get("b"); !ok {
+		t.Fatal("session expired before idle timeout")
+	}
+	s.mu.Lock()
+	lastSeen := s.items["b"].Value.(*entry).lastSeen
+	s.mu.Unlock()
+	if time.Since(lastSeen) > time.Minute {
+		t.Fatalf("Get did not refresh lastSeen (%s ago)", time.Since(lastSeen))
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []string // "put:x" 或 "get:x"
+		want []string
+		gone []string
+	}{
+		{
+			name: "oldest evicted",
+			ops:  []string{"put:a", "put:b", "put:c", "put:d"},
+			want: []string{"b", "c", "d"},
+			gone: []string{"a"},
+		},
+		{
+			name: "get protects",
+			ops:  []string{"put:a", "put:b", "put:c", "get:a", "put:d"},
+			want: []string{"a", "c", "d"},
+			gone: []string{"b"},
+		},
+		{
+			name: "overwrite does not grow",
+			ops:  []string{"put:a", "put:b", "put:a", "put:c"},
+			want: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore(Config{MaxEntries: 3})
+			defer s.Close()
+			for _, op := range tt.ops {
+				switch key := op[4:]; op[:4] {
+				case "put:":
+					s.Put(key, Data{UserID: key})
+				case "get:":
+					s.Get(key)
+				}
+			}
+			if s.Len() != len(tt.want) {
+				t.Fatalf("Len = %d, want %d", s.Len(), len(tt.want))
+			}
+			for _, k := range tt.want {
+				if d, ok := s.Get(k); !ok || d.UserID != k {
+					t.Errorf("Get(%q) = %+v, %v", k, d, ok)
+				}
+			}
+			for _, k := range tt.gone {
+				if _, ok := s.Get(k); ok {
+					t.Errorf("Get(%q) found evicted session", k)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	s := NewMemoryStore(Config{TTL: time.Hour})
+	defer s.Close()
+	now := time.Now()
+	s.mu.Lock()
+	s.put("old", Data{}, now.Add(-2*time.Hour), now)
+	s.put("new", Data{}, now, now)
+	s.mu.Unlock()
+	if n := s.Prune(); n != 1 {
+		t.Fatalf("Prune = %d, want 1", n)
+	}
+	if _, ok := s.Get("new"); !ok {
+		t.Fatal("live session pruned")
+	}
+}
@@ -0,0 +1,133 @@
+package signaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const defaultHandshakeTimeout = 10 * time.Second
+
+// LoginResult 为信令服务器对登录请求的应答。
+type LoginResult struct {
+	Accepted bool
+	Code     int
+	Message  string
+	Latency  time.Duration
+}
+
+// Client 是与信令服务器之间的一条 websocket 连接。
+type Client struct {
+	conn *websocket.Conn
+}
+
+// Dial 连接到信令服务器。
+//
+// addr 可以是 TanLobby 接口返回的 host:port，也可以是完整的 ws:// 或 wss:// 地址。
+func Dial(ctx context.Context, addr string) (*Client, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	location := signalingURL(addr)
+	origin := "http://" + strings.TrimPrefix(strings.TrimPrefix(location, "ws://"), "wss://")
+	cfg, err := websocket.NewConfig(location, origin)
+	if err != nil {
+		return nil, fmt.Errorf("signaling config: %w", err)
+	}
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dial signaling server: %w", err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Login 发送登录票据并等待服务器应答。
+//
+// 票据被拒绝时返回的 LoginResult.Accepted 为 false，并同时返回 ErrTicketRejected。
+func (c *Client) Login(ctx context.Context, cred Credentials) (LoginResult, error) {
+	var result LoginResult
+	if err := cred.validate(); err != nil {
+		return result, err
+	}
+	start := time.Now()
+	reply, err := c.roundTrip(ctx, cred.loginMessage(), msgTypeLoginResult)
+	if err != nil {
+		return result, err
+	}
+	result.Latency = time.Since(start)
+	result.Code = reply.Code
+	result.Message = reply.Message
+	result.Accepted = reply.Code == CodeOK
+	if !result.Accepted {
+		return result, fmt.Errorf("%w: %s(%d)", ErrTicketRejected, reply.Message, reply.Code)
+	}
+	return result, nil
+}
+
+// Ping 发送心跳并返回往返耗时。
+func (c *Client) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if _, err := c.roundTrip(ctx, message{Type: msgTypePing}, msgTypePong); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// Close 关闭连接。
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) roundTrip(ctx context.Context, req message, wantType string) (message, error) {
+	var reply message
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultHandshakeTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return reply, err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := websocket.JSON.Send(c.conn, req); err != nil {
+		return reply, fmt.Errorf("send %s: %w", req.Type, err)
+	}
+	if err := websocket.JSON.Receive(c.conn, &reply); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return reply, ctxErr
+		}
+		return reply, fmt.Errorf("receive %s: %w", wantType, err)
+	}
+	if reply.Type != wantType {
+		return reply, fmt.Errorf("%w: want %s, got %q", ErrUnexpectedMessage, wantType, reply.Type)
+	}
+	return reply, nil
+}
+
+// Probe 连接替身服务器并按模拟协议完成一次登录握手。
+func Probe(ctx context.Context, addr string, cred Credentials) (LoginResult, error) {
+	cli, err := Dial(ctx, addr)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	defer cli.Close()
+	return cli.Login(ctx, cred)
+}
+
+func signalingURL(addr string) string {
+	a := strings.TrimSpace(addr)
+	if strings.HasPrefix(a, "ws://") || strings.HasPrefix(a, "wss://") {
+		return a
+	}
+	if !strings.Contains(a, "/") {
+		a += "/"
+	}
+	return "ws://" + a
+}
@@ -0,0 +1,5 @@
+// Package signaling 只包含测试：以 websocket 上的模拟信令握手验证 SignalingSeed/SignalingTicket 的生成与解密是否一致。
+//
+// 测试中的 JSON 消息（login/login_result/ping/pong）与结果码（0/400/401/404）是 FunAuth 自定义的模拟协议，
+// 并非网易信令服务器的真实协议，因此客户端与替身服务器都只作为测试夹具存在，不会连接真实的信令服务器。
+package signaling
@@ -0,0 +1,97 @@
+package signaling
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yeah114/FunAuth/auth/tankey"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	srv := NewServer(TokenVerifier(map[uint32]string{42: testToken}))
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return srv, strings.TrimPrefix(ts.URL, "http://")
+}
+
+func testCredentials(t *testing.T, uid uint32, token string) Credentials {
+	t.Helper()
+	seed := []byte("0123456789abcdef")
+	ticket, err := tankey.EncryptTicket(seed, token, tankey.FormatBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Credentials{UserUniqueID: uid, Seed: seed, Ticket: ticket}
+}
+
+func TestProbeHandshake(t *testing.T) {
+	srv, addr := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		cred     Credentials
+		wantCode int
+	}{
+		{"accepted", testCredentials(t, 42, testToken), CodeOK},
+		{"wrong token", testCredentials(t, 42, strings.Repeat("x", len(testToken))), CodeTicketInvalid},
+		{"unknown user", testCredentials(t, 7, testToken), CodeUnknownUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Probe(ctx, addr, tt.cred)
+			if res.Code != tt.wantCode {
+				t.Fatalf("code = %d (%s), want %d", res.Code, res.Message, tt.wantCode)
+			}
+			if tt.wantCode == CodeOK {
+				if err != nil || !res.Accepted {
+					t.Fatalf("Probe = %+v, %v; want accepted", res, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrTicketRejected) || res.Accepted {
+				t.Fatalf("Probe = %+v, %v; want ErrTicketRejected", res, err)
+			}
+		})
+	}
+
+	if accepted, rejected := srv.Stats(); accepted != 1 || rejected != 2 {
+		t.Fatalf("Stats = %d accepted, %d rejected; want 1, 2", accepted, rejected)
+	}
+}
+
+func TestPingAfterLogin(t *testing.T) {
+	_, addr := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cli, err := Dial(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if _, err := cli.Login(ctx, testCredentials(t, 42, testToken)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginValidatesCredentials(t *testing.T) {
+	_, addr := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := Probe(ctx, addr, Credentials{UserUniqueID: 42}); err == nil {
+		t.Fatal("Probe with empty seed and ticket succeeded")
+	}
+}
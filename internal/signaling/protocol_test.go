@@ -0,0 +1,85 @@
+package signaling
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	msgTypeLogin       = "login"
+	msgTypeLoginResult = "login_result"
+	msgTypePing        = "ping"
+	msgTypePong        = "pong"
+)
+
+// 模拟协议的登录结果码。
+const (
+	CodeOK            = 0
+	CodeBadRequest    = 400
+	CodeTicketInvalid = 401
+	CodeUnknownUser   = 404
+)
+
+var (
+	// ErrTicketRejected 表示信令服务器拒绝了登录票据。
+	ErrTicketRejected = errors.New("signaling ticket rejected")
+	// ErrUnexpectedMessage 表示收到了不符合协议的消息。
+	ErrUnexpectedMessage = errors.New("unexpected signaling message")
+)
+
+// message 为信令 websocket 上传输的 JSON 文本帧。
+type message struct {
+	Type         string `json:"type"`
+	UserUniqueID uint32 `json:"uid,omitempty"`
+	Seed         string `json:"seed,omitempty"`
+	Ticket       string `json:"ticket,omitempty"`
+	Code         int    `json:"code"`
+	Message      string `json:"message,omitempty"`
+}
+
+// Credentials 为登录信令服务器所需的身份信息，
+// 对应 TanLobbyLogin/TanLobbyCreate 返回的 UserUniqueID、SignalingSeed 与 SignalingTicket。
+type Credentials struct {
+	UserUniqueID uint32
+	Seed         []byte
+	Ticket       []byte
+}
+
+func (c Credentials) validate() error {
+	if c.UserUniqueID == 0 {
+		return errors.New("missing user unique id")
+	}
+	if len(c.Seed) == 0 {
+		return errors.New("missing signaling seed")
+	}
+	if len(c.Ticket) == 0 {
+		return errors.New("missing signaling ticket")
+	}
+	return nil
+}
+
+func (c Credentials) loginMessage() message {
+	return message{
+		Type:         msgTypeLogin,
+		UserUniqueID: c.UserUniqueID,
+		Seed:         base64.StdEncoding.EncodeToString(c.Seed),
+		Ticket:       base64.StdEncoding.EncodeToString(c.Ticket),
+	}
+}
+
+func credentialsFromMessage(msg message) (Credentials, error) {
+	seed, err := base64.StdEncoding.DecodeString(msg.Seed)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("decode seed: %w", err)
+	}
+	ticket, err := base64.StdEncoding.DecodeString(msg.Ticket)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("decode ticket: %w", err)
+	}
+	cred := Credentials{UserUniqueID: msg.UserUniqueID, Seed: seed, Ticket: ticket}
+	if err := cred.validate(); err != nil {
+		return Credentials{}, err
+	}
+	return cred, nil
+}
@@ -0,0 +1,124 @@
+package signaling
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/Yeah114/FunAuth/auth/tankey"
+)
+
+// ErrUnknownUser 表示替身服务器中没有该用户的 token。
+var ErrUnknownUser = errors.New("unknown signaling user")
+
+// TicketVerifier 校验一组登录凭据，返回 nil 表示接受。
+type TicketVerifier func(cred Credentials) error
+
+// TokenVerifier 使用 uid -> UserToken 的映射解出票据中的 seed 并比对，
+// 票据按 tan_lobby_create 的 16 字节格式（tankey.FormatBlock）解密。
+func TokenVerifier(tokens map[uint32]string) TicketVerifier {
+	return func(cred Credentials) error {
+		token, ok := tokens[cred.UserUniqueID]
+		if !ok {
+			return ErrUnknownUser
+		}
+		seed, err := tankey.DecryptSignalingSeed(cred.Ticket, token, tankey.FormatBlock)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrTicketRejected, err)
+		}
+		if !bytes.Equal(seed, cred.Seed) {
+			return ErrTicketRejected
+		}
+		return nil
+	}
+}
+
+// Server 是信令服务器的本地替身，只实现登录握手与心跳。
+type Server struct {
+	verify TicketVerifier
+
+	mu       sync.Mutex
+	accepted int
+	rejected int
+}
+
+// NewServer 创建替身服务器。
+func NewServer(verify TicketVerifier) *Server {
+	return &Server{verify: verify}
+}
+
+// ServeHTTP 实现 http.Handler。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{Handler: s.serveConn}.ServeHTTP(w, r)
+}
+
+// Stats 返回已接受与已拒绝的登录次数。
+func (s *Server) Stats() (accepted, rejected int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted, s.rejected
+}
+
+func (s *Server) serveConn(conn *websocket.Conn) {
+	defer conn.Close()
+	loggedIn := false
+	for {
+		var msg message
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case msgTypeLogin:
+			reply := s.login(msg)
+			loggedIn = reply.Code == CodeOK
+			if err := websocket.JSON.Send(conn, reply); err != nil || !loggedIn {
+				return
+			}
+		case msgTypePing:
+			if err := websocket.JSON.Send(conn, message{Type: msgTypePong}); err != nil {
+				return
+			}
+		default:
+			_ = websocket.JSON.Send(conn, message{
+				Type:    msgTypeLoginResult,
+				Code:    CodeBadRequest,
+				Message: fmt.Sprintf("unexpected message type %q (logged in: %v)", msg.Type, loggedIn),
+			})
+			return
+		}
+	}
+}
+
+func (s *Server) login(msg message) message {
+	reply := message{Type: msgTypeLoginResult, Code: CodeOK, Message: "ok"}
+	cred, err := credentialsFromMessage(msg)
+	if err == nil && s.verify != nil {
+		err = s.verify(cred)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownUser):
+			reply.Code = CodeUnknownUser
+		case errors.Is(err, ErrTicketRejected):
+			reply.Code = CodeTicketInvalid
+		default:
+			reply.Code = CodeBadRequest
+		}
+		reply.Message = err.Error()
+	}
+
+	s.mu.Lock()
+	if reply.Code == CodeOK {
+		s.accepted++
+	} else {
+		s.rejected++
+	}
+	s.mu.Unlock()
+	log.Printf("[signaling] login uid=%d code=%d", msg.UserUniqueID, reply.Code)
+	return reply
+}
@@ -0,0 +1,325 @@
+// Package tenant 管理 FunAuth 服务的租户：每个租户持有若干 API Key 或客户端证书，
+// 并限定可访问的接口、可使用的账号以及每小时/每日的登录次数。
+package tenant
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrEndpointDenied 表示租户无权访问该接口。
+	ErrEndpointDenied = errors.New("endpoint not allowed for this api key")
+	// ErrAccountDenied 表示租户无权使用该账号。
+	ErrAccountDenied = errors.New("account not allowed for this api key")
+	// ErrScopeDenied 表示租户无权签发该权限范围的令牌。
+	ErrScopeDenied = errors.New("token scope not allowed for this api key")
+	// ErrQuotaExceeded 表示租户的登录次数已达上限。
+	ErrQuotaExceeded = errors.New("login quota exceeded")
+)
+
+// Quota 为登录次数限制，0 表示不限。
+type Quota struct {
+	HourlyLogins int `json:"hourly_logins" yaml:"hourly_logins" toml:"hourly_logins"`
+	DailyLogins  int `json:"daily_logins" yaml:"daily_logins" toml:"daily_logins"`
+}
+
+// Tenant 为一个租户的配置。
+type Tenant struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	// APIKeys 为该租户可用的 API Key。
+	APIKeys []string `json:"api_keys" yaml:"api_keys" toml:"api_keys"`
+	// Endpoints 为允许访问的路由，如 "/api/phoenix/login"；以 "*" 结尾表示前缀匹配，为空时允许全部。
+	Endpoints []string `json:"endpoints" yaml:"endpoints" toml:"endpoints"`
+	// Accounts 为允许使用的网易账号 user id，为空时不限。
+	Accounts []string `json:"accounts" yaml:"accounts" toml:"accounts"`
+	// ClientCerts 为映射到该租户的客户端证书（mTLS），格式见 CertIdentities，如 "cn:bot-1"、"sha256:<hex>"。
+	ClientCerts []string `json:"client_certs" yaml:"client_certs" toml:"client_certs"`
+	// Scopes 为允许通过 /api/new 签发的令牌权限范围，为空时可签发全部权限范围。
+	Scopes []string `json:"scopes" yaml:"scopes" toml:"scopes"`
+	Quota  Quota    `json:"quota" yaml:"quota" toml:"quota"`
+}
+
+// File 为租户配置文件（JSON）的结构。
+type File struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// AllowsEndpoint 报告租户是否可以访问路由 path。
+func (t *Tenant) AllowsEndpoint(path string) bool {
+	if len(t.Endpoints) == 0 {
+		return true
+	}
+	for _, p := range t.Endpoints {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAccount 报告租户是否可以使用账号 userID。
+func (t *Tenant) AllowsAccount(userID string) bool {
+	return len(t.Accounts) == 0 || slices.Contains(t.Accounts, userID)
+}
+
+// AllowsScope 报告租户是否可以签发权限范围为 scope 的令牌。
+func (t *Tenant) AllowsScope(scope string) bool {
+	return len(t.Scopes) == 0 || slices.Contains(t.Scopes, scope)
+}
+
+// Usage 为租户在当前时间窗口内的用量。
+type Usage struct {
+	Tenant       string           `json:"tenant"`
+	HourlyLogins int              `json:"hourly_logins"`
+	HourlyLimit  int              `json:"hourly_limit"`
+	HourResetAt  time.Time        `json:"hour_reset_at"`
+	DailyLogins  int              `json:"daily_logins"`
+	DailyLimit   int              `json:"daily_limit"`
+	DayResetAt   time.Time        `json:"day_reset_at"`
+	Requests     map[string]int64 `json:"requests"`
+}
+
+// counter 为一个租户的用量计数；小时与日窗口按 UTC 整点/零点切换。
+type counter struct {
+	mu       sync.Mutex
+	hour     time.Time
+	day      time.Time
+	hourly   int
+	daily    int
+	requests map[string]int64
+}
+
+func (c *counter) rollLocked(now time.Time) {
+	now = now.UTC()
+	if h := now.Truncate(time.Hour); !h.Equal(c.hour) {
+		c.hour, c.hourly = h, 0
+	}
+	if d := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); !d.Equal(c.day) {
+		c.day, c.daily = d, 0
+	}
+}
+
+// Registry 按 API Key 查找租户并记录用量，可在运行时整体替换租户配置。
+type Registry struct {
+	mu      sync.RWMutex
+	byKey   map[[sha256.Size]byte]*Tenant
+	byCert  map[string]*Tenant
+	tenants []Tenant
+
+	countersMu sync.Mutex
+	counters   map[string]*counter // 以租户名为键，替换配置后用量保留
+
+	now func() time.Time
+}
+
+// NewRegistry 以 tenants 创建注册表。
+func NewRegistry(tenants []Tenant) (*Registry, error) {
+	r := &Registry{counters: make(map[string]*counter), now: time.Now}
+	if err := r.Replace(tenants); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load 从 JSON 文件读取租户配置。
+func Load(path string) ([]Tenant, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return f.Tenants, nil
+}
+
+// Replace 校验并替换全部租户配置。
+func (r *Registry) Replace(tenants []Tenant) error {
+	byKey := make(map[[sha256.Size]byte]*Tenant)
+	byCert := make(map[string]*Tenant)
+	names := make(map[string]bool, len(tenants))
+	list := slices.Clone(tenants)
+	for i := range list {
+		t := &list[i]
+		if t.Name == "" {
+			return fmt.Errorf("tenant #%d: empty name", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("tenant %q: duplicate name", t.Name)
+		}
+		names[t.Name] = true
+		if len(t.APIKeys) == 0 && len(t.ClientCerts) == 0 {
+			return fmt.Errorf("tenant %q: no api keys or client certs", t.Name)
+		}
+		for _, k := range t.APIKeys {
+			if k == "" {
+				return fmt.Errorf("tenant %q: empty api key", t.Name)
+			}
+			h := sha256.Sum256([]byte(k))
+			if _, dup := byKey[h]; dup {
+				return fmt.Errorf("tenant %q: api key shared with another tenant", t.Name)
+			}
+			byKey[h] = t
+		}
+		for _, id := range t.ClientCerts {
+			id, err := normalizeCertIdentity(id)
+			if err != nil {
+				return fmt.Errorf("tenant %q: %w", t.Name, err)
+			}
+			if _, dup := byCert[id]; dup {
+				return fmt.Errorf("tenant %q: client cert %q mapped to another tenant", t.Name, id)
+			}
+			byCert[id] = t
+		}
+	}
+	r.mu.Lock()
+	r.byKey, r.byCert, r.tenants = byKey, byCert, list
+	r.mu.Unlock()
+	return nil
+}
+
+// Lookup 返回 API Key 对应的租户。
+func (r *Registry) Lookup(apiKey string) (*Tenant, bool) {
+	if apiKey == "" {
+		return nil, false
+	}
+	h := sha256.Sum256([]byte(apiKey))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byKey[h]
+	return t, ok
+}
+
+// LookupCert 返回已校验的客户端证书对应的租户。
+func (r *Registry) LookupCert(cert *x509.Certificate) (*Tenant, bool) {
+	if cert == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, id := range CertIdentities(cert) {
+		if t, ok := r.byCert[id]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// CertIdentities 返回证书可用于 client_certs 匹配的标识，按优先级排列：
+// "sha256:<证书 DER 的 SHA-256 十六进制>"、"cn:<Subject CN>"、"dns:<SAN>"、"email:<SAN>"、"uri:<SAN>"。
+func CertIdentities(cert *x509.Certificate) []string {
+	sum := sha256.Sum256(cert.Raw)
+	ids := []string{"sha256:" + hex.EncodeToString(sum[:])}
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, "cn:"+cert.Subject.CommonName)
+	}
+	for _, v := range cert.DNSNames {
+		ids = append(ids, "dns:"+v)
+	}
+	for _, v := range cert.EmailAddresses {
+		ids = append(ids, "email:"+v)
+	}
+	for _, v := range cert.URIs {
+		ids = append(ids, "uri:"+v.String())
+	}
+	return ids
+}
+
+func normalizeCertIdentity(id string) (string, error) {
+	kind, value, ok := strings.Cut(strings.TrimSpace(id), ":")
+	kind = strings.ToLower(kind)
+	if !ok || value == "" {
+		return "", fmt.Errorf("bad client cert %q, want kind:value", id)
+	}
+	switch kind {
+	case "sha256":
+		value = strings.ToLower(strings.ReplaceAll(value, ":", ""))
+		if b, err := hex.DecodeString(value); err != nil || len(b) != sha256.Size {
+			return "", fmt.Errorf("bad client cert fingerprint %q", id)
+		}
+	case "cn", "dns", "email", "uri":
+	default:
+		return "", fmt.Errorf("bad client cert %q, kind must be sha256, cn, dns, email or uri", id)
+	}
+	return kind + ":" + value, nil
+}
+
+// Len 返回租户数量。
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.tenants)
+}
+
+func (r *Registry) counter(name string) *counter {
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+	c, ok := r.counters[name]
+	if !ok {
+		c = &counter{requests: make(map[string]int64)}
+		r.counters[name] = c
+	}
+	return c
+}
+
+// RecordRequest 记录一次对路由 path 的请求。
+func (r *Registry) RecordRequest(t *Tenant, path string) {
+	c := r.counter(t.Name)
+	c.mu.Lock()
+	c.requests[path]++
+	c.mu.Unlock()
+}
+
+// ReserveLogin 在未超出配额时占用一次登录次数；登录失败也计入配额。
+func (r *Registry) ReserveLogin(t *Tenant) error {
+	c := r.counter(t.Name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollLocked(r.now())
+	if t.Quota.HourlyLogins > 0 && c.hourly >= t.Quota.HourlyLogins {
+		return ErrQuotaExceeded
+	}
+	if t.Quota.DailyLogins > 0 && c.daily >= t.Quota.DailyLogins {
+		return ErrQuotaExceeded
+	}
+	c.hourly++
+	c.daily++
+	return nil
+}
+
+// Usage 返回租户当前的用量。
+func (r *Registry) Usage(t *Tenant) Usage {
+	c := r.counter(t.Name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollLocked(r.now())
+	u := Usage{
+		Tenant:       t.Name,
+		HourlyLogins: c.hourly,
+		HourlyLimit:  t.Quota.HourlyLogins,
+		HourResetAt:  c.hour.Add(time.Hour),
+		DailyLogins:  c.daily,
+		DailyLimit:   t.Quota.DailyLogins,
+		DayResetAt:   c.day.AddDate(0, 0, 1),
+		Requests:     make(map[string]int64, len(c.requests)),
+	}
+	for k, v := range c.requests {
+		u.Requests[k] = v
+	}
+	return u
+}
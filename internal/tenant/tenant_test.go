@@ -0,0 +1,188 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllows(t *testing.T) {
+	ten := &Tenant{
+		Endpoints: []string{"/api/phoenix/login", "/api/tools/*"},
+		Accounts:  []string{"100"},
+		Scopes:    []string{"phoenix"},
+	}
+	open := &Tenant{}
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"exact endpoint", ten.AllowsEndpoint("/api/phoenix/login"), true},
+		{"prefix endpoint", ten.AllowsEndpoint("/api/tools/check_num_stats"), true},
+		{"other endpoint", ten.AllowsEndpoint("/api/phoenix/tan_lobby_login"), false},
+		{"endpoint is not a prefix", ten.AllowsEndpoint("/api/phoenix/login/x"), false},
+		{"listed account", ten.AllowsAccount("100"), true},
+		{"other account", ten.AllowsAccount("200"), false},
+		{"listed scope", ten.AllowsScope("phoenix"), true},
+		{"other scope", ten.AllowsScope("admin"), false},
+		{"no endpoint list", open.AllowsEndpoint("/anything"), true},
+		{"no account list", open.AllowsAccount("200"), true},
+		{"no scope list", open.AllowsScope("admin"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants []Tenant
+		wantErr string
+	}{
+		{"ok", []Tenant{{Name: "a", APIKeys: []string{"k1"}}, {Name: "b", ClientCerts: []string{"cn:bot"}}}, ""},
+		{"empty name", []Tenant{{APIKeys: []string{"k1"}}}, "empty name"},
+		{"duplicate name", []Tenant{{Name: "a", APIKeys: []string{"k1"}}, {Name: "a", APIKeys: []string{"k2"}}}, "duplicate name"},
+		{"no credentials", []Tenant{{Name: "a"}}, "no api keys"},
+		{"empty key", []Tenant{{Name: "a", APIKeys: []string{""}}}, "empty api key"},
+		{"shared key", []Tenant{{Name: "a", APIKeys: []string{"k1"}}, {Name: "b", APIKeys: []string{"k1"}}}, "shared"},
+		{"bad cert kind", []Tenant{{Name: "a", ClientCerts: []string{"serial:1"}}}, "kind must be"},
+		{"bad fingerprint", []Tenant{{Name: "a", ClientCerts: []string{"sha256:abcd"}}}, "fingerprint"},
+		{"shared cert", []Tenant{{Name: "a", ClientCerts: []string{"cn:bot"}}, {Name: "b", ClientCerts: []string{"CN:bot"}}}, "another tenant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.tenants)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewRegistry = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("der"), Subject: pkix.Name{CommonName: "bot-1"}, DNSNames: []string{"bot.example"}}
+	sum := sha256.Sum256(cert.Raw)
+	r, err := NewRegistry([]Tenant{
+		{Name: "keys", APIKeys: []string{"secret"}},
+		{Name: "cn", ClientCerts: []string{"cn:bot-1"}},
+		{Name: "pin", ClientCerts: []string{"sha256:" + strings.ToUpper(hex.EncodeToString(sum[:]))}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ten, ok := r.Lookup("secret"); !ok || ten.Name != "keys" {
+		t.Fatalf("Lookup(secret) = %v, %v", ten, ok)
+	}
+	for _, key := range []string{"", "other"} {
+		if _, ok := r.Lookup(key); ok {
+			t.Fatalf("Lookup(%q) found a tenant", key)
+		}
+	}
+	// 证书指纹优先于 CN
+	if ten, ok := r.LookupCert(cert); !ok || ten.Name != "pin" {
+		t.Fatalf("LookupCert = %v, %v; want pin", ten, ok)
+	}
+	if _, ok := r.LookupCert(nil); ok {
+		t.Fatal("LookupCert(nil) found a tenant")
+	}
+}
+
+func TestReserveLogin(t *testing.T) {
+	type step struct {
+		at   string // UTC 时间
+		want error
+	}
+	tests := []struct {
+		name  string
+		quota Quota
+		steps []step
+	}{
+		{
+			name:  "hourly",
+			quota: Quota{HourlyLogins: 2},
+			steps: []step{
+				{"10:00", nil},
+				{"10:30", nil},
+				{"10:59", ErrQuotaExceeded},
+				{"11:00", nil},
+			},
+		},
+		{
+			name:  "daily",
+			quota: Quota{DailyLogins: 2},
+			steps: []step{
+				{"10:00", nil},
+				{"15:00", nil},
+				{"23:59", ErrQuotaExceeded},
+			},
+		},
+		{
+			name: "unlimited",
+			steps: []step{
+				{"10:00", nil},
+				{"10:00", nil},
+				{"10:00", nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ten := Tenant{Name: "a", APIKeys: []string{"k"}, Quota: tt.quota}
+			r, err := NewRegistry([]Tenant{ten})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.steps {
+				at, err := time.Parse("2006-01-02 15:04", "2024-05-01 "+s.at)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.now = func() time.Time { return at }
+				if err := r.ReserveLogin(&ten); !errors.Is(err, s.want) {
+					t.Fatalf("ReserveLogin at %s = %v, want %v", s.at, err, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUsageSurvivesReplace(t *testing.T) {
+	ten := Tenant{Name: "a", APIKeys: []string{"k1"}, Quota: Quota{HourlyLogins: 5}}
+	r, err := NewRegistry([]Tenant{ten})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.RecordRequest(&ten, "/api/phoenix/login")
+	if err := r.ReserveLogin(&ten); err != nil {
+		t.Fatal(err)
+	}
+
+	ten.APIKeys = []string{"k2"}
+	if err := r.Replace([]Tenant{ten}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Lookup("k1"); ok {
+		t.Fatal("old api key still valid after Replace")
+	}
+	u := r.Usage(&ten)
+	if u.HourlyLogins != 1 || u.Requests["/api/phoenix/login"] != 1 {
+		t.Fatalf("Usage after Replace = %+v", u)
+	}
+}
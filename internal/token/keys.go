@@ -0,0 +1,101 @@
+package token
+
+import (
+	cryptoRand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// minSecretLen 为签名密钥的最短长度（字节）。
+const minSecretLen = 16
+
+// Key 为一把 HMAC-SHA256 签名密钥。
+type Key struct {
+	// ID 写入令牌，用于验证时查找密钥，不能包含 '.'、','、':'。
+	ID     string
+	Secret []byte
+}
+
+// KeyRing 为一组签名密钥；第一把为当前用于签发的密钥，其余仅用于验证，
+// 轮换时把新密钥放在最前，旧密钥保留到其签发的令牌全部过期后再移除。
+type KeyRing struct {
+	keys   map[string][]byte
+	active string
+}
+
+// NewKeyRing 以 keys 创建密钥环，keys[0] 为签发密钥。
+func NewKeyRing(keys ...Key) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("token: empty key ring")
+	}
+	r := &KeyRing{keys: make(map[string][]byte, len(keys)), active: keys[0].ID}
+	for _, k := range keys {
+		if k.ID == "" || strings.ContainsAny(k.ID, ".,:") {
+			return nil, fmt.Errorf("token: invalid key id %q", k.ID)
+		}
+		if len(k.Secret) < minSecretLen {
+			return nil, fmt.Errorf("token: key %q shorter than %d bytes", k.ID, minSecretLen)
+		}
+		if _, dup := r.keys[k.ID]; dup {
+			return nil, fmt.Errorf("token: duplicate key id %q", k.ID)
+		}
+		r.keys[k.ID] = k.Secret
+	}
+	return r, nil
+}
+
+// ParseKeyRing 解析 "kid:secret,kid2:secret2" 形式的密钥列表，
+// secret 以 "hex:" 开头时按十六进制解码，否则按原始字符串使用。
+func ParseKeyRing(spec string) (*KeyRing, error) {
+	var keys []Key
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, errors.New("token: bad key, want kid:secret")
+		}
+		raw := []byte(secret)
+		if h, isHex := strings.CutPrefix(secret, "hex:"); isHex {
+			var err error
+			if raw, err = hex.DecodeString(h); err != nil {
+				return nil, fmt.Errorf("token: key %q: %w", id, err)
+			}
+		}
+		keys = append(keys, Key{ID: strings.TrimSpace(id), Secret: raw})
+	}
+	return NewKeyRing(keys...)
+}
+
+// EphemeralKeyRing 生成一把随机密钥，进程重启后之前签发的令牌全部失效。
+func EphemeralKeyRing() *KeyRing {
+	secret := make([]byte, 32)
+	if _, err := cryptoRand.Read(secret); err != nil {
+		panic(err)
+	}
+	r, _ := NewKeyRing(Key{ID: "ephemeral", Secret: secret})
+	return r
+}
+
+// ActiveID 返回当前签发密钥的 ID。
+func (r *KeyRing) ActiveID() string { return r.active }
+
+// IDs 返回全部密钥 ID，签发密钥在前。
+func (r *KeyRing) IDs() []string {
+	ids := []string{r.active}
+	for id := range r.keys {
+		if id != r.active {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (r *KeyRing) lookup(id string) ([]byte, bool) {
+	k, ok := r.keys[id]
+	return k, ok
+}
@@ -0,0 +1,45 @@
+package token
+
+import (
+	"log"
+	"time"
+)
+
+const defaultTTL = 24 * time.Hour
+
+// Options 为签发器配置。
+type Options struct {
+	// Keys 为签名密钥 "kid:secret,kid2:secret2"，第一把用于签发；为空时使用进程内随机密钥。
+	Keys string
+	// TTL 为令牌最长有效期，<=0 时为 24h。
+	TTL time.Duration
+	// RevocationFile 为吊销列表文件，为空时吊销记录仅保存在内存中。
+	RevocationFile string
+}
+
+// Open 按 opts 创建签发器。
+func Open(opts Options) (*Issuer, error) {
+	ring := EphemeralKeyRing()
+	if opts.Keys != "" {
+		var err error
+		if ring, err = ParseKeyRing(opts.Keys); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Printf("[token] no signing keys configured, using an ephemeral signing key; tokens will not survive a restart")
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	revoked := NewRevocations()
+	if opts.RevocationFile != "" {
+		var err error
+		if revoked, err = LoadRevocations(opts.RevocationFile); err != nil {
+			return nil, err
+		}
+	}
+	return NewIssuer(ring, ttl, revoked), nil
+}
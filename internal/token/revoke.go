@@ -0,0 +1,104 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Revocations 记录被吊销的令牌 ID，条目在令牌过期后自动清除；
+// 配置了文件路径时每次吊销都会原子地写回文件，重启后仍然有效。
+type Revocations struct {
+	mu   sync.Mutex
+	ids  map[string]int64 // jti -> 令牌过期时间（Unix 秒）
+	path string
+}
+
+// NewRevocations 创建仅保存在内存中的吊销列表。
+func NewRevocations() *Revocations {
+	return &Revocations{ids: make(map[string]int64)}
+}
+
+// LoadRevocations 从 path 加载吊销列表，文件不存在时返回空列表。
+func LoadRevocations(path string) (*Revocations, error) {
+	r := NewRevocations()
+	r.path = path
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &r.ids); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if r.ids == nil {
+		r.ids = make(map[string]int64)
+	}
+	r.pruneLocked(time.Now().Unix())
+	return r, nil
+}
+
+// Add 吊销 id，直到 expiry 之后清除。
+func (r *Revocations) Add(id string, expiry time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(time.Now().Unix())
+	r.ids[id] = expiry.Unix()
+	return r.saveLocked()
+}
+
+// Revoked 报告 id 是否已被吊销。
+func (r *Revocations) Revoked(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.ids[id]
+	return ok
+}
+
+// Len 返回当前吊销条目数。
+func (r *Revocations) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.ids)
+}
+
+func (r *Revocations) pruneLocked(now int64) {
+	for id, exp := range r.ids {
+		if now >= exp {
+			delete(r.ids, id)
+		}
+	}
+}
+
+func (r *Revocations) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+	raw, err := json.Marshal(r.ids)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
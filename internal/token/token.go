@@ -0,0 +1,170 @@
+// Package token 签发和验证 /api/new 下发的 Bearer 令牌。
+//
+// 令牌格式为 "fa1.<kid>.<payload>.<mac>"，payload 为 base64url 编码的 JSON Claims，
+// mac 为 HMAC-SHA256("fa1.<kid>.<payload>") 的 base64url 编码。
+package token
+
+import (
+	"crypto/hmac"
+	cryptoRand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const version = "fa1"
+
+var (
+	// ErrMalformed 表示令牌格式不正确。
+	ErrMalformed = errors.New("malformed token")
+	// ErrUnknownKey 表示令牌使用的签名密钥不在密钥环中。
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrBadSignature 表示令牌签名不匹配。
+	ErrBadSignature = errors.New("bad token signature")
+	// ErrExpired 表示令牌已过期。
+	ErrExpired = errors.New("token expired")
+	// ErrRevoked 表示令牌已被吊销。
+	ErrRevoked = errors.New("token revoked")
+	// ErrScope 表示令牌不包含所需的权限范围。
+	ErrScope = errors.New("token scope not granted")
+)
+
+// Claims 为令牌携带的声明。
+type Claims struct {
+	ID        string   `json:"jti"`
+	KeyID     string   `json:"-"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+	Scopes    []string `json:"scp,omitempty"`
+}
+
+// Allows 报告令牌是否包含 scope；未限定范围的令牌允许全部操作。
+func (c Claims) Allows(scope string) bool {
+	return len(c.Scopes) == 0 || slices.Contains(c.Scopes, scope)
+}
+
+// Expiry 返回过期时间。
+func (c Claims) Expiry() time.Time { return time.Unix(c.ExpiresAt, 0) }
+
+// Issuer 签发、验证与吊销令牌。
+type Issuer struct {
+	mu     sync.RWMutex
+	ring   *KeyRing
+	ttl    time.Duration
+	revoke *Revocations
+	now    func() time.Time
+}
+
+// NewIssuer 创建签发器；ttl 为签发令牌的默认及最长有效期，revoked 可为 nil。
+func NewIssuer(ring *KeyRing, ttl time.Duration, revoked *Revocations) *Issuer {
+	if revoked == nil {
+		revoked = NewRevocations()
+	}
+	return &Issuer{ring: ring, ttl: ttl, revoke: revoked, now: time.Now}
+}
+
+// SetKeyRing 替换密钥环，用于不重启地轮换密钥。
+func (i *Issuer) SetKeyRing(ring *KeyRing) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.ring = ring
+}
+
+// KeyRing 返回当前密钥环。
+func (i *Issuer) KeyRing() *KeyRing {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.ring
+}
+
+// TTL 返回签发令牌的最长有效期。
+func (i *Issuer) TTL() time.Duration { return i.ttl }
+
+// Issue 签发令牌；ttl<=0 或超过上限时使用上限。
+func (i *Issuer) Issue(scopes []string, ttl time.Duration) (string, Claims, error) {
+	if ttl <= 0 || ttl > i.ttl {
+		ttl = i.ttl
+	}
+	id := make([]byte, 16)
+	if _, err := cryptoRand.Read(id); err != nil {
+		return "", Claims{}, err
+	}
+	now := i.now()
+	claims := Claims{
+		ID:        hex.EncodeToString(id),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		Scopes:    scopes,
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	ring := i.KeyRing()
+	claims.KeyID = ring.ActiveID()
+	secret, _ := ring.lookup(claims.KeyID)
+	signed := version + "." + claims.KeyID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + sign(secret, signed), claims, nil
+}
+
+// Verify 校验令牌的签名、有效期与吊销状态。
+func (i *Issuer) Verify(tok string) (Claims, error) {
+	claims, err := i.parse(tok)
+	if err != nil {
+		return claims, err
+	}
+	if i.now().Unix() >= claims.ExpiresAt {
+		return claims, ErrExpired
+	}
+	if i.revoke.Revoked(claims.ID) {
+		return claims, ErrRevoked
+	}
+	return claims, nil
+}
+
+// Revoke 吊销令牌，只要签名有效即可吊销（包括已过期的令牌）。
+func (i *Issuer) Revoke(tok string) (Claims, error) {
+	claims, err := i.parse(tok)
+	if err != nil {
+		return claims, err
+	}
+	return claims, i.revoke.Add(claims.ID, claims.Expiry())
+}
+
+func (i *Issuer) parse(tok string) (Claims, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 4 || parts[0] != version {
+		return Claims{}, ErrMalformed
+	}
+	secret, ok := i.KeyRing().lookup(parts[1])
+	if !ok {
+		return Claims{}, ErrUnknownKey
+	}
+	signed := parts[0] + "." + parts[1] + "." + parts[2]
+	if !hmac.Equal([]byte(sign(secret, signed)), []byte(parts[3])) {
+		return Claims{}, ErrBadSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ID == "" {
+		return Claims{}, ErrMalformed
+	}
+	claims.KeyID = parts[1]
+	return claims, nil
+}
+
+func sign(secret []byte, signed string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,204 @@
+package token
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKeys    = "k1:0123456789abcdef0123"
+	rotatedKeys = "k2:abcdefabcdefabcdefab,k1:0123456789abcdef0123"
+)
+
+func newTestIssuer(t *testing.T, spec string, now *time.Time) *Issuer {
+	t.Helper()
+	ring, err := ParseKeyRing(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := NewIssuer(ring, time.Hour, nil)
+	i.now = func() time.Time { return *now }
+	return i
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	i := newTestIssuer(t, testKeys, &now)
+	tok, _, err := i.Issue([]string{"phoenix"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, _, err := i.Issue(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.Revoke(revoked); err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(tok, ".")
+	other, _, err := i.Issue([]string{"admin"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPayload := strings.Split(other, ".")[2]
+
+	tests := []struct {
+		name  string
+		tok   string
+		after time.Duration
+		want  error
+	}{
+		{"valid", tok, 0, nil},
+		{"valid before expiry", tok, time.Hour - time.Second, nil},
+		{"expired", tok, time.Hour, ErrExpired},
+		{"revoked", revoked, 0, ErrRevoked},
+		{"swapped payload", strings.Join([]string{parts[0], parts[1], otherPayload, parts[3]}, "."), 0, ErrBadSignature},
+		{"truncated mac", tok[:len(tok)-2], 0, ErrBadSignature},
+		{"unknown key", strings.Join([]string{parts[0], "k9", parts[2], parts[3]}, "."), 0, ErrUnknownKey},
+		{"wrong version", "fa0" + tok[3:], 0, ErrMalformed},
+		{"missing part", strings.Join(parts[:3], "."), 0, ErrMalformed},
+		{"empty", "", 0, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := now.Add(tt.after)
+			i.now = func() time.Time { return at }
+			claims, err := i.Verify(tt.tok)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (!claims.Allows("phoenix") || claims.Allows("admin") || claims.KeyID != "k1") {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestIssueTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	i := newTestIssuer(t, testKeys, &now)
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"default", 0, time.Hour},
+		{"shorter", 10 * time.Minute, 10 * time.Minute},
+		{"capped", 48 * time.Hour, time.Hour},
+		{"negative", -time.Minute, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, claims, err := i.Issue(nil, tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := claims.Expiry().Sub(now); got != tt.want {
+				t.Fatalf("lifetime = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	i := newTestIssuer(t, testKeys, &now)
+	old, _, err := i.Issue(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := ParseKeyRing(rotatedKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.SetKeyRing(rotated)
+	fresh, claims, err := i.Issue(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.KeyID != "k2" {
+		t.Fatalf("signed with %q after rotation, want k2", claims.KeyID)
+	}
+	for name, tok := range map[string]string{"old": old, "fresh": fresh} {
+		if _, err := i.Verify(tok); err != nil {
+			t.Errorf("Verify %s token after rotation = %v", name, err)
+		}
+	}
+
+	retired, err := ParseKeyRing("k2:abcdefabcdefabcdefab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.SetKeyRing(retired)
+	if _, err := i.Verify(old); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Verify old token after retiring k1 = %v, want ErrUnknownKey", err)
+	}
+	if _, err := i.Verify(fresh); err != nil {
+		t.Fatalf("Verify fresh token after retiring k1 = %v", err)
+	}
+}
+
+func TestParseKeyRing(t *testing.T) {
+	tests := []struct {
+		spec    string
+		active  string
+		wantErr bool
+	}{
+		{testKeys, "k1", false},
+		{rotatedKeys, "k2", false},
+		{" k1 : 0123456789abcdef0123 , ", "k1", false},
+		{"k1:hex:000102030405060708090a0b0c0d0e0f", "k1", false},
+		{"", "", true},
+		{"k1", "", true},
+		{"k1:short", "", true},
+		{"k1:hex:zz", "", true},
+		{"k.1:0123456789abcdef0123", "", true},
+		{"k1:0123456789abcdef0123,k1:abcdefabcdefabcdefab", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			ring, err := ParseKeyRing(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseKeyRing accepted %q", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ring.ActiveID() != tt.active {
+				t.Fatalf("ActiveID = %q, want %q", ring.ActiveID(), tt.active)
+			}
+		})
+	}
+}
+
+func TestRevocationsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revoked.json")
+	r, err := LoadRevocations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("live", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("stale", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = LoadRevocations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Revoked("live") {
+		t.Fatal("revocation lost after reload")
+	}
+	if r.Revoked("stale") {
+		t.Fatal("expired revocation kept after reload")
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package workerpool
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// procGroup 为子进程所在的进程组，结束时连同子进程启动的 python 等进程一起结束。
+type procGroup struct {
+	pgid int
+}
+
+// prepareGroup 让子进程在启动时成为新进程组的组长。
+func prepareGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func newProcGroup(cmd *exec.Cmd) (*procGroup, error) {
+	return &procGroup{pgid: cmd.Process.Pid}, nil
+}
+
+func (g *procGroup) kill() error {
+	return syscall.Kill(-g.pgid, syscall.SIGKILL)
+}
+
+func (g *procGroup) close() {}
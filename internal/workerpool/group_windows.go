@@ -0,0 +1,55 @@
+//go:build windows
+
+package workerpool
+
+import (
+	"fmt"
+	"os/exec"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// procGroup 为子进程所在的作业对象，结束时连同子进程启动的 python 等进程一起结束。
+type procGroup struct {
+	job windows.Handle
+}
+
+func prepareGroup(cmd *exec.Cmd) {}
+
+// newProcGroup 把刚启动的子进程加入新的作业对象；子进程在收到第一个请求前不会再启动其他进程。
+func newProcGroup(cmd *exec.Cmd) (*procGroup, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create job object: %w", err)
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		return nil, fmt.Errorf("set job object limits: %w", err)
+	}
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return nil, fmt.Errorf("open worker process: %w", err)
+	}
+	defer windows.CloseHandle(proc)
+	if err := windows.AssignProcessToJobObject(job, proc); err != nil {
+		windows.CloseHandle(job)
+		return nil, fmt.Errorf("assign worker to job object: %w", err)
+	}
+	return &procGroup{job: job}, nil
+}
+
+func (g *procGroup) kill() error {
+	return windows.TerminateJobObject(g.job, 1)
+}
+
+func (g *procGroup) close() {
+	windows.CloseHandle(g.job)
+}
@@ -0,0 +1,364 @@
+// Package workerpool 维护一组常驻的子进程，通过按行分隔的协议与之通信：
+// 每次调用向 stdin 写入一行请求，并从 stdout 读取一行应答。
+//
+// 子进程崩溃、输出异常或单次调用超时时会被结束，并在下一次调用前自动重启；
+// 结束时连同子进程启动的其他进程（所在进程组，Windows 上为作业对象）一起结束，不会留下孤儿进程。
+// 调用方取消 ctx 只会让 Call 提前返回，子进程仍会处理完当前请求并继续服务。
+package workerpool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeout     = 15 * time.Second
+	maxLineSize        = 16 << 20
+	restartBackoffBase = 200 * time.Millisecond
+	restartBackoffMax  = 5 * time.Second
+)
+
+var (
+	// ErrClosed 表示进程池已关闭。
+	ErrClosed = errors.New("worker pool closed")
+	// ErrTimeout 表示单次调用超时，对应的子进程已被结束。
+	ErrTimeout = errors.New("worker call timed out")
+)
+
+// Config 为进程池配置。
+type Config struct {
+	// Name 用于日志前缀。
+	Name string
+	// Command 为子进程命令及参数。
+	Command []string
+	// Env 为追加到当前环境变量之后的额外变量。
+	Env []string
+	// Size 为子进程数量，<=0 时为 1。
+	Size int
+	// Timeout 为单次调用超时，<=0 时为 15s。
+	Timeout time.Duration
+}
+
+// Stats 为进程池运行状态。
+type Stats struct {
+	Size       int   `json:"size"`
+	Busy       int64 `json:"busy"`
+	QueueDepth int64 `json:"queue_depth"`
+	Calls      int64 `json:"calls"`
+	Failures   int64 `json:"failures"`
+	Timeouts   int64 `json:"timeouts"`
+	Restarts   int64 `json:"restarts"`
+}
+
+type job struct {
+	ctx   context.Context
+	line  []byte
+	reply chan jobResult
+}
+
+type jobResult struct {
+	line []byte
+	err  error
+}
+
+// Pool 为常驻子进程池。
+type Pool struct {
+	cfg  Config
+	jobs chan *job
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	closeOnce sync.Once
+
+	busy       atomic.Int64
+	queueDepth atomic.Int64
+	calls      atomic.Int64
+	failures   atomic.Int64
+	timeouts   atomic.Int64
+	restarts   atomic.Int64
+}
+
+// New 创建进程池；子进程在首次调用时启动。
+func New(cfg Config) (*Pool, error) {
+	if len(cfg.Command) == 0 {
+		return nil, errors.New("workerpool: empty command")
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Name == "" {
+		cfg.Name = "workerpool"
+	}
+	p := &Pool{
+		cfg:  cfg,
+		jobs: make(chan *job),
+		done: make(chan struct{}),
+	}
+	for i := 0; i < cfg.Size; i++ {
+		p.wg.Add(1)
+		go p.runWorker(i)
+	}
+	return p, nil
+}
+
+// Call 发送一行请求并返回子进程的一行应答（均不含换行符）。
+func (p *Pool) Call(ctx context.Context, line []byte) ([]byte, error) {
+	if bytes.ContainsAny(line, "\r\n") {
+		return nil, errors.New("workerpool: request must be a single line")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	j := &job{ctx: ctx, line: line, reply: make(chan jobResult, 1)}
+
+	p.calls.Add(1)
+	p.queueDepth.Add(1)
+	select {
+	case p.jobs <- j:
+		p.queueDepth.Add(-1)
+	case <-ctx.Done():
+		p.queueDepth.Add(-1)
+		p.failures.Add(1)
+		return nil, ctx.Err()
+	case <-p.done:
+		p.queueDepth.Add(-1)
+		return nil, ErrClosed
+	}
+
+	res := <-j.reply
+	if res.err != nil {
+		p.failures.Add(1)
+	}
+	return res.line, res.err
+}
+
+// Stats 返回当前运行状态。
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Size:       p.cfg.Size,
+		Busy:       p.busy.Load(),
+		QueueDepth: p.queueDepth.Load(),
+		Calls:      p.calls.Load(),
+		Failures:   p.failures.Load(),
+		Timeouts:   p.timeouts.Load(),
+		Restarts:   p.restarts.Load(),
+	}
+}
+
+// Close 结束所有子进程。
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+	return nil
+}
+
+func (p *Pool) runWorker(idx int) {
+	defer p.wg.Done()
+	var proc *process
+	defer func() {
+		if proc != nil {
+			proc.kill()
+		}
+	}()
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-p.done:
+			return
+		case j := <-p.jobs:
+			p.busy.Add(1)
+			if proc == nil {
+				if err := p.wait(j.ctx, backoff); err != nil {
+					p.busy.Add(-1)
+					j.reply <- jobResult{err: err}
+					if errors.Is(err, ErrClosed) {
+						return
+					}
+					continue
+				}
+				var err error
+				proc, err = p.start(idx)
+				if err != nil {
+					backoff = nextBackoff(backoff)
+					p.busy.Add(-1)
+					j.reply <- jobResult{err: err}
+					continue
+				}
+			}
+			if err := j.ctx.Err(); err != nil {
+				p.busy.Add(-1)
+				j.reply <- jobResult{err: err}
+				continue
+			}
+			replied := false
+			line, err := proc.call(j.ctx, j.line, p.cfg.Timeout, func(err error) {
+				replied = true
+				j.reply <- jobResult{err: err}
+			})
+			if err != nil {
+				if errors.Is(err, ErrTimeout) {
+					p.timeouts.Add(1)
+				}
+				log.Printf("[%s] worker %d (pid=%d) failed, restarting: %v", p.cfg.Name, idx, proc.pid(), err)
+				proc.kill()
+				proc = nil
+				p.restarts.Add(1)
+				backoff = nextBackoff(backoff)
+			} else {
+				backoff = 0
+			}
+			p.busy.Add(-1)
+			if !replied {
+				j.reply <- jobResult{line: line, err: err}
+			}
+		}
+	}
+}
+
+// wait 在重启子进程前等待 d；进程池关闭或调用方取消时提前返回。
+func (p *Pool) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-p.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	if d == 0 {
+		return restartBackoffBase
+	}
+	d *= 2
+	if d > restartBackoffMax {
+		d = restartBackoffMax
+	}
+	return d
+}
+
+type process struct {
+	cmd    *exec.Cmd
+	group  *procGroup
+	stdin  io.WriteCloser
+	lines  chan []byte
+	readEr chan error
+}
+
+func (p *Pool) start(idx int) (*process, error) {
+	cmd := exec.Command(p.cfg.Command[0], p.cfg.Command[1:]...)
+	cmd.Env = append(os.Environ(), p.cfg.Env...)
+	cmd.Stderr = os.Stderr
+	prepareGroup(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%s: stdin pipe: %w", p.cfg.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%s: stdout pipe: %w", p.cfg.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s: start worker: %w", p.cfg.Name, err)
+	}
+	group, err := newProcGroup(cmd)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("%s: %w", p.cfg.Name, err)
+	}
+	proc := &process{
+		cmd:    cmd,
+		group:  group,
+		stdin:  stdin,
+		lines:  make(chan []byte),
+		readEr: make(chan error, 1),
+	}
+	go proc.readLoop(stdout)
+	log.Printf("[%s] worker %d started (pid=%d)", p.cfg.Name, idx, cmd.Process.Pid)
+	return proc, nil
+}
+
+func (pr *process) readLoop(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for sc.Scan() {
+		pr.lines <- bytes.Clone(sc.Bytes())
+	}
+	err := sc.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	pr.readEr <- err
+}
+
+// call 写入一行请求并等待应答。ctx 结束时通过 cancelled 让调用方先返回，
+// 但仍等待并丢弃本次应答（最长到 timeout），以免后续请求读到错位的应答而误杀正常的子进程。
+func (pr *process) call(ctx context.Context, line []byte, timeout time.Duration, cancelled func(error)) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	if _, err := pr.stdin.Write(append(bytes.Clone(line), '\n')); err != nil {
+		return nil, fmt.Errorf("write request: %w", err)
+	}
+	ctxDone := ctx.Done()
+	for {
+		select {
+		case out := <-pr.lines:
+			return out, nil
+		case err := <-pr.readEr:
+			return nil, fmt.Errorf("worker exited: %w", err)
+		case <-timer.C:
+			return nil, ErrTimeout
+		case <-ctxDone:
+			cancelled(ctx.Err())
+			ctxDone = nil
+		}
+	}
+}
+
+func (pr *process) pid() int {
+	if pr.cmd.Process == nil {
+		return 0
+	}
+	return pr.cmd.Process.Pid
+}
+
+// kill 结束子进程及其所在进程组中的全部进程。
+func (pr *process) kill() {
+	_ = pr.stdin.Close()
+	if err := pr.group.kill(); err != nil {
+		_ = pr.cmd.Process.Kill()
+	}
+	go func() {
+		// 排空读取协程，避免其阻塞在 lines 上
+		for {
+			select {
+			case <-pr.lines:
+			case <-pr.readEr:
+				_ = pr.cmd.Wait()
+				pr.group.close()
+				return
+			}
+		}
+	}()
+}
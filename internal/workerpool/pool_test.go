@@ -0,0 +1,122 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 设置该环境变量时测试程序作为 worker 运行：原样回显每行请求，
+// "exit" 使进程退出，"hang" 永不应答，"slow" 延迟 300ms 后应答。
+const workerEnv = "WORKERPOOL_TEST_WORKER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(workerEnv) == "1" {
+		runTestWorker()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func runTestWorker() {
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		switch line := sc.Text(); line {
+		case "exit":
+			os.Exit(3)
+		case "hang":
+			time.Sleep(time.Hour)
+		case "slow":
+			time.Sleep(300 * time.Millisecond)
+			fmt.Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+func newTestPool(t *testing.T, size int, timeout time.Duration) *Pool {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(Config{Name: "test", Command: []string{exe}, Env: []string{workerEnv + "=1"}, Size: size, Timeout: timeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestCall(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          string
+		wantErr      string
+		wantTimeouts int64
+		wantRestarts int64
+	}{
+		{name: "echo", req: "hello"},
+		{name: "multi-line request", req: "a\nb", wantErr: "single line"},
+		{name: "worker exits", req: "exit", wantErr: "worker exited", wantRestarts: 1},
+		{name: "worker hangs", req: "hang", wantErr: ErrTimeout.Error(), wantTimeouts: 1, wantRestarts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPool(t, 1, 500*time.Millisecond)
+			out, err := p.Call(context.Background(), []byte(tt.req))
+			if tt.wantErr == "" {
+				if err != nil || string(out) != tt.req {
+					t.Fatalf("Call = %q, %v; want %q", out, err, tt.req)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Call err = %v, want %q", err, tt.wantErr)
+			}
+			st := p.Stats()
+			if st.Timeouts != tt.wantTimeouts || st.Restarts != tt.wantRestarts {
+				t.Fatalf("Stats = %+v, want %d timeouts, %d restarts", st, tt.wantTimeouts, tt.wantRestarts)
+			}
+
+			// 失败后的子进程会被重启，下一次调用正常
+			if out, err := p.Call(context.Background(), []byte("again")); err != nil || string(out) != "again" {
+				t.Fatalf("Call after %s = %q, %v", tt.name, out, err)
+			}
+		})
+	}
+}
+
+func TestCancelledCallKeepsReplyOrder(t *testing.T) {
+	p := newTestPool(t, 1, 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.Call(ctx, []byte("slow")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancelled Call = %v", err)
+	}
+	out, err := p.Call(context.Background(), []byte("next"))
+	if err != nil || string(out) != "next" {
+		t.Fatalf("Call after cancellation = %q, %v; want the matching reply", out, err)
+	}
+	if st := p.Stats(); st.Restarts != 0 {
+		t.Fatalf("worker restarted after a cancelled call: %+v", st)
+	}
+}
+
+func TestClosed(t *testing.T) {
+	p := newTestPool(t, 2, time.Second)
+	p.Close()
+	if _, err := p.Call(context.Background(), []byte("x")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Call after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("New accepted an empty command")
+	}
+}